@@ -84,8 +84,19 @@ func InitDB() (*gorm.DB, error) {
 		&models.Site{},
 		&models.Device{},
 		&models.Feature{},
+		&models.FeatureHook{},
+		&models.Run{},
 		&models.Result{},
 		&models.ResultDetail{},
+		&models.ResultArtifact{},
+		&models.PageMetric{},
+		&models.PerformanceBudget{},
+		&models.ResultFinding{},
+		&models.ResultMetric{},
+		&models.ResultHook{},
+		&models.AuthSession{},
+		&models.Quarantine{},
+		&models.ResultComment{},
 	); err != nil {
 		return nil, fmt.Errorf("failed to migrate database: %v", err)
 	}
@@ -0,0 +1,511 @@
+package controllers
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"qa-automation-system/backend/models"
+)
+
+// AnalyticsController handles aggregated views over the test results
+type AnalyticsController struct {
+	DB *gorm.DB
+}
+
+// NewAnalyticsController creates a new analytics controller
+func NewAnalyticsController(db *gorm.DB) *AnalyticsController {
+	return &AnalyticsController{DB: db}
+}
+
+// PassRateTrend represents the outcome counts of a group of results for one day or week
+type PassRateTrend struct {
+	Period     string   `json:"period"`
+	GroupName  string   `json:"group"`
+	Total      int64    `json:"total"`
+	Passed     int64    `json:"passed"`
+	Failed     int64    `json:"failed"`
+	Warning    int64    `json:"warning"`
+	Processing int64    `json:"processing"`
+	PassRate   *float64 `json:"pass_rate"`
+}
+
+// trendIntervals maps the interval parameter to the SQL expression of the period start
+var trendIntervals = map[string]string{
+	"day":  "DATE_FORMAT(results.created_at, '%Y-%m-%d')",
+	"week": "DATE_FORMAT(DATE_SUB(DATE(results.created_at), INTERVAL WEEKDAY(results.created_at) DAY), '%Y-%m-%d')",
+}
+
+// trendGroups maps the group_by parameter to the SQL expression of the group name
+var trendGroups = map[string]string{
+	"":                 "'all'",
+	"site":             "COALESCE(sites.name, 'N/A')",
+	"feature":          "COALESCE(features.name, 'N/A')",
+	"browser":          "COALESCE(results.browser, 'N/A')",
+	"device":           "COALESCE(devices.name, 'N/A')",
+	"failure_category": "COALESCE(results.failure_category, 'none')",
+}
+
+// GetTrends returns the result counts and pass rate per day or week (interval, weeks start on Monday),
+// optionally per site, feature, browser, device or failure category (group_by). The pass rate is the
+// percentage of passed results among the finished ones, null when none finished. Retried attempts
+// are left out, only the final attempt of a result is counted.
+func (ac *AnalyticsController) GetTrends(c *gin.Context) {
+	from, to, err := parseDateRange(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid date, expected YYYY-MM-DD"})
+		return
+	}
+
+	interval := c.DefaultQuery("interval", "day")
+	period, ok := trendIntervals[interval]
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid interval, expected day or week"})
+		return
+	}
+	groupBy := c.Query("group_by")
+	group, ok := trendGroups[groupBy]
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid group_by, expected site, feature, browser, device or failure_category"})
+		return
+	}
+
+	query := ac.DB.Table("results").
+		Select(fmt.Sprintf(`%s AS period,
+			%s AS group_name,
+			COUNT(*) AS total,
+			SUM(CASE WHEN results.status = 'passed' THEN 1 ELSE 0 END) AS passed,
+			SUM(CASE WHEN results.status = 'failed' THEN 1 ELSE 0 END) AS failed,
+			SUM(CASE WHEN results.status = 'warning' THEN 1 ELSE 0 END) AS warning,
+			SUM(CASE WHEN results.status = 'processing' THEN 1 ELSE 0 END) AS processing,
+			ROUND(100 * SUM(CASE WHEN results.status = 'passed' THEN 1 ELSE 0 END) /
+				NULLIF(SUM(CASE WHEN results.status <> 'processing' THEN 1 ELSE 0 END), 0), 2) AS pass_rate`, period, group)).
+		Joins("LEFT JOIN sites ON sites.id = results.site_id").
+		Joins("LEFT JOIN features ON features.id = results.feature_id").
+		Joins("LEFT JOIN devices ON devices.id = results.device_id").
+		Where("results.created_at >= ? AND results.created_at < ?", from, to).
+		// Only the final attempt of a retried result counts
+		Where("NOT EXISTS (SELECT 1 FROM results retries WHERE retries.retry_of_id = results.id)")
+
+	// Apply filters if they exist
+	if siteID := c.Query("site_id"); siteID != "" {
+		query = query.Where("results.site_id = ?", siteID)
+	}
+	if deviceID := c.Query("device_id"); deviceID != "" {
+		query = query.Where("results.device_id = ?", deviceID)
+	}
+	if featureID := c.Query("feature_id"); featureID != "" {
+		query = query.Where("results.feature_id = ?", featureID)
+	}
+	if browser := c.Query("browser"); browser != "" {
+		query = query.Where("results.browser = ?", browser)
+	}
+	if category := c.Query("failure_category"); category != "" {
+		query = query.Where("results.failure_category IN ?", strings.Split(category, ","))
+	}
+
+	var trends []PassRateTrend
+	if err := query.
+		Group("period, group_name").
+		Order("period ASC, group_name ASC").
+		Scan(&trends).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch pass-rate trends"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": trends,
+		"meta": gin.H{
+			"from":     from.Format("2006-01-02"),
+			"to":       to.AddDate(0, 0, -1).Format("2006-01-02"),
+			"interval": interval,
+			"group_by": groupBy,
+		},
+	})
+}
+
+// FailureCount represents the failed results of a failure category in a group
+type FailureCount struct {
+	FailureCategory string  `json:"failure_category"`
+	GroupName       string  `json:"group"`
+	Count           int64   `json:"count"`
+	Share           float64 `json:"share"`
+}
+
+// GetFailures returns the failed results per failure category, optionally per site, feature, browser
+// or device (group_by), with the share of the category among the failures of its group. Failures
+// recorded before they were classified count as unclassified.
+func (ac *AnalyticsController) GetFailures(c *gin.Context) {
+	from, to, err := parseDateRange(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid date, expected YYYY-MM-DD"})
+		return
+	}
+
+	groupBy := c.Query("group_by")
+	group, ok := trendGroups[groupBy]
+	if !ok || groupBy == "failure_category" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid group_by, expected site, feature, browser or device"})
+		return
+	}
+
+	query := ac.DB.Table("results").
+		Select(fmt.Sprintf(`COALESCE(results.failure_category, 'unclassified') AS failure_category,
+			%s AS group_name,
+			COUNT(*) AS count`, group)).
+		Joins("LEFT JOIN sites ON sites.id = results.site_id").
+		Joins("LEFT JOIN features ON features.id = results.feature_id").
+		Joins("LEFT JOIN devices ON devices.id = results.device_id").
+		Where("results.status = ?", "failed").
+		Where("results.created_at >= ? AND results.created_at < ?", from, to)
+
+	// Apply filters if they exist
+	if siteID := c.Query("site_id"); siteID != "" {
+		query = query.Where("results.site_id = ?", siteID)
+	}
+	if deviceID := c.Query("device_id"); deviceID != "" {
+		query = query.Where("results.device_id = ?", deviceID)
+	}
+	if featureID := c.Query("feature_id"); featureID != "" {
+		query = query.Where("results.feature_id = ?", featureID)
+	}
+	if browser := c.Query("browser"); browser != "" {
+		query = query.Where("results.browser = ?", browser)
+	}
+
+	var counts []FailureCount
+	if err := query.
+		Group("failure_category, group_name").
+		Order("group_name ASC, count DESC").
+		Scan(&counts).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch failure categories"})
+		return
+	}
+
+	totals := make(map[string]int64)
+	for _, count := range counts {
+		totals[count.GroupName] += count.Count
+	}
+	for i := range counts {
+		counts[i].Share = math.Round(float64(counts[i].Count)/float64(totals[counts[i].GroupName])*10000) / 100
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": counts,
+		"meta": gin.H{
+			"from":       from.Format("2006-01-02"),
+			"to":         to.AddDate(0, 0, -1).Format("2006-01-02"),
+			"group_by":   groupBy,
+			"categories": models.FailureCategories,
+		},
+	})
+}
+
+// FlakyCombination represents the flakiness of a site, feature, browser and device combination
+// over its recent finished results
+type FlakyCombination struct {
+	SiteID          uint      `json:"site_id"`
+	SiteName        string    `json:"site_name"`
+	FeatureID       uint      `json:"feature_id"`
+	FeatureName     string    `json:"feature_name"`
+	Browser         string    `json:"browser"`
+	DeviceID        uint      `json:"device_id"`
+	DeviceName      string    `json:"device_name"`
+	Runs            int       `json:"runs"`
+	Failures        int       `json:"failures"`
+	Flips           int       `json:"flips"`
+	FlipRate        float64   `json:"flip_rate"`
+	RetryPasses     int       `json:"retry_passes"`
+	PassOnRetryRate float64   `json:"pass_on_retry_rate"`
+	Score           float64   `json:"score"`
+	Flaky           bool      `json:"flaky"`
+	QuarantineID    *uint     `json:"quarantine_id"`
+	LastStatus      string    `json:"last_status"`
+	LastRunAt       time.Time `json:"last_run_at"`
+}
+
+// flakyHistoryRow is a finished result of a combination
+type flakyHistoryRow struct {
+	SiteID      uint
+	SiteName    string
+	FeatureID   uint
+	FeatureName string
+	Browser     string
+	DeviceID    uint
+	DeviceName  string
+	Status      string
+	CreatedAt   time.Time
+	// PassedOnRetry is set when earlier attempts of the result failed
+	PassedOnRetry bool
+}
+
+// queryInt reads a positive integer query parameter
+func queryInt(c *gin.Context, name string, fallback int) (int, error) {
+	value := c.Query(name)
+	if value == "" {
+		return fallback, nil
+	}
+	parsed, err := strconv.Atoi(value)
+	if err != nil || parsed < 1 {
+		return 0, fmt.Errorf("Invalid %s, expected a positive integer", name)
+	}
+	return parsed, nil
+}
+
+// scoreFlakiness scores the final attempts of a combination, ordered oldest first. A flip is a
+// change between failed and passing (passed or warning) from one result to the next; a pass on
+// retry is a result marked passed_on_retry. The score averages the flip rate and the share of
+// failed first attempts that passed on retry, from 0 (stable) to 1.
+func scoreFlakiness(history []flakyHistoryRow) FlakyCombination {
+	last := history[len(history)-1]
+	combination := FlakyCombination{
+		SiteID:      last.SiteID,
+		SiteName:    last.SiteName,
+		FeatureID:   last.FeatureID,
+		FeatureName: last.FeatureName,
+		Browser:     last.Browser,
+		DeviceID:    last.DeviceID,
+		DeviceName:  last.DeviceName,
+		Runs:        len(history),
+		LastStatus:  last.Status,
+		LastRunAt:   last.CreatedAt,
+	}
+
+	for i, row := range history {
+		if row.Status == "failed" {
+			combination.Failures++
+		}
+		if row.PassedOnRetry {
+			combination.RetryPasses++
+		}
+		if i == 0 {
+			continue
+		}
+		previous := history[i-1]
+		if (previous.Status == "failed") != (row.Status == "failed") {
+			combination.Flips++
+		}
+	}
+
+	if len(history) > 1 {
+		combination.FlipRate = float64(combination.Flips) / float64(len(history)-1)
+	}
+	// Every pass on retry followed a failed first attempt
+	if failedFirst := combination.Failures + combination.RetryPasses; failedFirst > 0 {
+		combination.PassOnRetryRate = float64(combination.RetryPasses) / float64(failedFirst)
+	}
+	combination.Score = math.Round((combination.FlipRate+combination.PassOnRetryRate)/2*1000) / 1000
+	combination.FlipRate = math.Round(combination.FlipRate*1000) / 1000
+	combination.PassOnRetryRate = math.Round(combination.PassOnRetryRate*1000) / 1000
+
+	return combination
+}
+
+// GetFlaky returns the flakiness score of every combination with at least min_runs (default 5)
+// finished results in the last days (default 30), scoring the latest window (default 20) of them.
+// Combinations scoring threshold (default 0.3) or more are flaky; results are sorted by score.
+func (ac *AnalyticsController) GetFlaky(c *gin.Context) {
+	days, err := queryInt(c, "days", 30)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	window, err := queryInt(c, "window", 20)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	minRuns, err := queryInt(c, "min_runs", 5)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	threshold := 0.3
+	if value := c.Query("threshold"); value != "" {
+		threshold, err = strconv.ParseFloat(value, 64)
+		if err != nil || threshold < 0 || threshold > 1 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid threshold, expected a number from 0 to 1"})
+			return
+		}
+	}
+
+	query := ac.DB.Table("results").
+		Select(`results.site_id, sites.name AS site_name,
+			results.feature_id, features.name AS feature_name,
+			results.browser, results.device_id, devices.name AS device_name,
+			results.status, results.passed_on_retry, results.created_at`).
+		Joins("JOIN sites ON sites.id = results.site_id").
+		Joins("JOIN features ON features.id = results.feature_id").
+		Joins("JOIN devices ON devices.id = results.device_id").
+		Where("results.status <> ? AND results.browser IS NOT NULL", "processing").
+		Where("results.created_at >= ?", time.Now().AddDate(0, 0, -days)).
+		// Retried attempts are scored through the passed_on_retry of their final attempt
+		Where("NOT EXISTS (SELECT 1 FROM results retries WHERE retries.retry_of_id = results.id)")
+
+	// Apply filters if they exist
+	if siteID := c.Query("site_id"); siteID != "" {
+		query = query.Where("results.site_id = ?", siteID)
+	}
+	if deviceID := c.Query("device_id"); deviceID != "" {
+		query = query.Where("results.device_id = ?", deviceID)
+	}
+	if featureID := c.Query("feature_id"); featureID != "" {
+		query = query.Where("results.feature_id = ?", featureID)
+	}
+	if browser := c.Query("browser"); browser != "" {
+		query = query.Where("results.browser = ?", browser)
+	}
+
+	var rows []flakyHistoryRow
+	if err := query.
+		Order("results.site_id, results.feature_id, results.browser, results.device_id, results.created_at, results.id").
+		Scan(&rows).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch result history"})
+		return
+	}
+
+	var quarantines []models.Quarantine
+	if err := ac.DB.Find(&quarantines).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	combinations := []FlakyCombination{}
+	for start := 0; start < len(rows); {
+		end := start
+		for end < len(rows) && rows[end].SiteID == rows[start].SiteID && rows[end].FeatureID == rows[start].FeatureID &&
+			rows[end].Browser == rows[start].Browser && rows[end].DeviceID == rows[start].DeviceID {
+			end++
+		}
+
+		history := rows[start:end]
+		start = end
+		if len(history) > window {
+			history = history[len(history)-window:]
+		}
+		if len(history) < minRuns {
+			continue
+		}
+
+		combination := scoreFlakiness(history)
+		combination.Flaky = combination.Score >= threshold
+		for _, quarantine := range quarantines {
+			if quarantine.Matches(models.Result{SiteID: combination.SiteID, FeatureID: combination.FeatureID,
+				Browser: combination.Browser, DeviceID: combination.DeviceID}) {
+				id := quarantine.ID
+				combination.QuarantineID = &id
+				break
+			}
+		}
+		combinations = append(combinations, combination)
+	}
+
+	sort.SliceStable(combinations, func(i, j int) bool {
+		return combinations[i].Score > combinations[j].Score
+	})
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": combinations,
+		"meta": gin.H{
+			"days":      days,
+			"window":    window,
+			"min_runs":  minRuns,
+			"threshold": threshold,
+		},
+	})
+}
+
+// markQuarantined flags the results that belong to a quarantined combination
+func markQuarantined(db *gorm.DB, results []models.Result) error {
+	if len(results) == 0 {
+		return nil
+	}
+
+	var quarantines []models.Quarantine
+	if err := db.Where("site_id = ?", results[0].SiteID).Find(&quarantines).Error; err != nil {
+		return err
+	}
+
+	for i := range results {
+		for _, quarantine := range quarantines {
+			if quarantine.Matches(results[i]) {
+				results[i].Quarantined = true
+				break
+			}
+		}
+	}
+	return nil
+}
+
+// GetQuarantines retrieves all quarantined combinations
+func (ac *AnalyticsController) GetQuarantines(c *gin.Context) {
+	var quarantines []models.Quarantine
+	if err := ac.DB.Preload("Site").Preload("Feature").Preload("Device").Order("id DESC").Find(&quarantines).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, quarantines)
+}
+
+// CreateQuarantine quarantines a site, feature, browser and device combination
+func (ac *AnalyticsController) CreateQuarantine(c *gin.Context) {
+	var payload struct {
+		SiteID    uint   `json:"site_id" binding:"required"`
+		FeatureID uint   `json:"feature_id" binding:"required"`
+		Browser   string `json:"browser" binding:"required"`
+		DeviceID  uint   `json:"device_id" binding:"required"`
+		Reason    string `json:"reason"`
+	}
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var count int64
+	if err := ac.DB.Model(&models.Quarantine{}).Where("site_id = ? AND feature_id = ? AND browser = ? AND device_id = ?",
+		payload.SiteID, payload.FeatureID, payload.Browser, payload.DeviceID).Count(&count).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if count > 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "Combination is already quarantined"})
+		return
+	}
+
+	quarantine := models.Quarantine{
+		SiteID:    payload.SiteID,
+		FeatureID: payload.FeatureID,
+		Browser:   payload.Browser,
+		DeviceID:  payload.DeviceID,
+		Reason:    payload.Reason,
+	}
+	if err := ac.DB.Omit("Site", "Feature", "Device").Create(&quarantine).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, quarantine)
+}
+
+// DeleteQuarantine lifts the quarantine of a combination
+func (ac *AnalyticsController) DeleteQuarantine(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
+	}
+
+	if err := ac.DB.Delete(&models.Quarantine{}, id).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Quarantine lifted successfully"})
+}
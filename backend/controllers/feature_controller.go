@@ -1,12 +1,14 @@
 package controllers
 
 import (
+	"fmt"
 	"net/http"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
 	"qa-automation-system/backend/models"
+	"qa-automation-system/backend/pkg/testrunner"
 )
 
 // FeatureController handles feature-related operations
@@ -19,6 +21,42 @@ func NewFeatureController(db *gorm.DB) *FeatureController {
 	return &FeatureController{DB: db}
 }
 
+// validateRetryPolicy checks the attempts, failure categories and backoff of a retry policy
+func validateRetryPolicy(policy models.RetryPolicy) error {
+	if policy.MaxAttempts < 0 || policy.MaxAttempts > models.MaxRetryAttempts {
+		return fmt.Errorf("invalid retry max_attempts: %d, expected 0 to %d", policy.MaxAttempts, models.MaxRetryAttempts)
+	}
+
+	for _, category := range policy.Categories() {
+		valid := category == models.RetryAny
+		for _, known := range models.FailureCategories {
+			if category == known {
+				valid = true
+				break
+			}
+		}
+		if !valid {
+			return fmt.Errorf("invalid retry category: %s", category)
+		}
+	}
+
+	if policy.Backoff < 0 || policy.Backoff > models.MaxRetryBackoff {
+		return fmt.Errorf("invalid retry backoff: %d, expected 0 to %d seconds", policy.Backoff, models.MaxRetryBackoff)
+	}
+
+	return nil
+}
+
+// validateHooks checks the setup and teardown hooks of a feature
+func validateHooks(hooks []models.FeatureHook) error {
+	for _, hook := range hooks {
+		if err := testrunner.ValidateHook(hook); err != nil {
+			return fmt.Errorf("invalid hook %q: %v", hook.Name, err)
+		}
+	}
+	return nil
+}
+
 // Create handles the creation of a new feature
 func (c *FeatureController) Create(ctx *gin.Context) {
 	var feature models.Feature
@@ -27,6 +65,16 @@ func (c *FeatureController) Create(ctx *gin.Context) {
 		return
 	}
 
+	if err := validateRetryPolicy(feature.Retry); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := validateHooks(feature.Hooks); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
 	if err := c.DB.Create(&feature).Error; err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -55,7 +103,9 @@ func (c *FeatureController) GetByID(ctx *gin.Context) {
 	}
 
 	var feature models.Feature
-	if err := c.DB.First(&feature, id).Error; err != nil {
+	if err := c.DB.Preload("Hooks", func(db *gorm.DB) *gorm.DB {
+		return db.Order("phase DESC, position ASC, id ASC")
+	}).First(&feature, id).Error; err != nil {
 		ctx.JSON(http.StatusNotFound, gin.H{"error": "Feature not found"})
 		return
 	}
@@ -82,7 +132,36 @@ func (c *FeatureController) Update(ctx *gin.Context) {
 		return
 	}
 
-	if err := c.DB.Save(&feature).Error; err != nil {
+	if err := validateRetryPolicy(feature.Retry); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := validateHooks(feature.Hooks); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Hooks are kept unless the request sends them, then they replace the stored ones
+	if err := c.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Hooks").Save(&feature).Error; err != nil {
+			return err
+		}
+		if feature.Hooks == nil {
+			return tx.Where("feature_id = ?", feature.ID).Order("position ASC, id ASC").Find(&feature.Hooks).Error
+		}
+		if err := tx.Where("feature_id = ?", feature.ID).Delete(&models.FeatureHook{}).Error; err != nil {
+			return err
+		}
+		for i := range feature.Hooks {
+			feature.Hooks[i].ID = 0
+			feature.Hooks[i].FeatureID = feature.ID
+		}
+		if len(feature.Hooks) > 0 {
+			return tx.Create(&feature.Hooks).Error
+		}
+		return nil
+	}); err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
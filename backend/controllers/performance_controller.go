@@ -0,0 +1,234 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"qa-automation-system/backend/models"
+)
+
+// PerformanceController handles page performance operations
+type PerformanceController struct {
+	DB *gorm.DB
+}
+
+// NewPerformanceController creates a new performance controller
+func NewPerformanceController(db *gorm.DB) *PerformanceController {
+	return &PerformanceController{DB: db}
+}
+
+// PageLoadTrend represents the average page timings of a site page for one day
+type PageLoadTrend struct {
+	Date                   string   `json:"date"`
+	SiteID                 uint     `json:"site_id"`
+	SiteName               string   `json:"site_name"`
+	Page                   string   `json:"page"`
+	Samples                int64    `json:"samples"`
+	TimeToFirstByte        float64  `json:"ttfb"`
+	DOMContentLoaded       float64  `json:"dom_content_loaded"`
+	LoadEventEnd           float64  `json:"load_event_end"`
+	FirstContentfulPaint   *float64 `json:"first_contentful_paint"`
+	LargestContentfulPaint *float64 `json:"largest_contentful_paint"`
+	CumulativeLayoutShift  *float64 `json:"cumulative_layout_shift"`
+}
+
+// parseDateRange reads the from/to query parameters (YYYY-MM-DD), defaulting to the last 30 days.
+// The returned end time is exclusive.
+func parseDateRange(c *gin.Context) (time.Time, time.Time, error) {
+	now := time.Now()
+	from := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local).AddDate(0, 0, -29)
+	to := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local).AddDate(0, 0, 1)
+
+	if value := c.Query("from"); value != "" {
+		parsed, err := time.ParseInLocation("2006-01-02", value, time.Local)
+		if err != nil {
+			return from, to, err
+		}
+		from = parsed
+	}
+	if value := c.Query("to"); value != "" {
+		parsed, err := time.ParseInLocation("2006-01-02", value, time.Local)
+		if err != nil {
+			return from, to, err
+		}
+		to = parsed.AddDate(0, 0, 1)
+	}
+
+	return from, to, nil
+}
+
+// GetTrends returns the daily average page-load timings per site and page
+func (pc *PerformanceController) GetTrends(c *gin.Context) {
+	from, to, err := parseDateRange(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid date, expected YYYY-MM-DD"})
+		return
+	}
+
+	query := pc.DB.Table("page_metrics").
+		Select(`DATE_FORMAT(page_metrics.created_at, '%Y-%m-%d') AS date,
+			results.site_id AS site_id,
+			sites.name AS site_name,
+			page_metrics.page AS page,
+			COUNT(*) AS samples,
+			AVG(page_metrics.time_to_first_byte) AS time_to_first_byte,
+			AVG(page_metrics.dom_content_loaded) AS dom_content_loaded,
+			AVG(page_metrics.load_event_end) AS load_event_end,
+			AVG(page_metrics.first_contentful_paint) AS first_contentful_paint,
+			AVG(page_metrics.largest_contentful_paint) AS largest_contentful_paint,
+			AVG(page_metrics.cumulative_layout_shift) AS cumulative_layout_shift`).
+		Joins("JOIN results ON results.id = page_metrics.result_id").
+		Joins("JOIN sites ON sites.id = results.site_id").
+		Where("page_metrics.created_at >= ? AND page_metrics.created_at < ?", from, to)
+
+	// Apply filters if they exist
+	if siteID := c.Query("site_id"); siteID != "" {
+		query = query.Where("results.site_id = ?", siteID)
+	}
+	if deviceID := c.Query("device_id"); deviceID != "" {
+		query = query.Where("results.device_id = ?", deviceID)
+	}
+	if browser := c.Query("browser"); browser != "" {
+		query = query.Where("results.browser = ?", browser)
+	}
+	if page := c.Query("page"); page != "" {
+		query = query.Where("page_metrics.page = ?", page)
+	}
+
+	var trends []PageLoadTrend
+	if err := query.
+		Group("date, results.site_id, sites.name, page_metrics.page").
+		Order("date ASC, sites.name ASC, page_metrics.page ASC").
+		Scan(&trends).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch performance trends"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": trends,
+		"meta": gin.H{
+			"from": from.Format("2006-01-02"),
+			"to":   to.AddDate(0, 0, -1).Format("2006-01-02"),
+		},
+	})
+}
+
+// validateBudget checks the metric name, severity and threshold of a budget
+func validateBudget(budget *models.PerformanceBudget) error {
+	validMetric := false
+	for _, name := range models.PageMetricNames {
+		if budget.Metric == name {
+			validMetric = true
+			break
+		}
+	}
+	if !validMetric {
+		return fmt.Errorf("invalid metric: %s", budget.Metric)
+	}
+
+	if budget.Severity == "" {
+		budget.Severity = "warning"
+	}
+	if budget.Severity != "warning" && budget.Severity != "failed" {
+		return fmt.Errorf("invalid severity: %s, expected warning or failed", budget.Severity)
+	}
+
+	if budget.Threshold <= 0 {
+		return fmt.Errorf("threshold must be greater than zero")
+	}
+
+	if budget.SiteID == 0 || budget.Page == "" {
+		return fmt.Errorf("site_id and page are required")
+	}
+
+	return nil
+}
+
+// GetBudgets retrieves all performance budgets, optionally filtered by site
+func (pc *PerformanceController) GetBudgets(c *gin.Context) {
+	query := pc.DB.Preload("Site")
+	if siteID := c.Query("site_id"); siteID != "" {
+		query = query.Where("site_id = ?", siteID)
+	}
+
+	var budgets []models.PerformanceBudget
+	if err := query.Order("site_id ASC, page ASC").Find(&budgets).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, budgets)
+}
+
+// CreateBudget handles the creation of a new performance budget
+func (pc *PerformanceController) CreateBudget(c *gin.Context) {
+	var budget models.PerformanceBudget
+	if err := c.ShouldBindJSON(&budget); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := validateBudget(&budget); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := pc.DB.Omit("Site").Create(&budget).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, budget)
+}
+
+// UpdateBudget handles updating a performance budget
+func (pc *PerformanceController) UpdateBudget(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
+	}
+
+	var budget models.PerformanceBudget
+	if err := pc.DB.First(&budget, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Performance budget not found"})
+		return
+	}
+
+	if err := c.ShouldBindJSON(&budget); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := validateBudget(&budget); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := pc.DB.Omit("Site").Save(&budget).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, budget)
+}
+
+// DeleteBudget handles deleting a performance budget
+func (pc *PerformanceController) DeleteBudget(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
+	}
+
+	if err := pc.DB.Delete(&models.PerformanceBudget{}, id).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Performance budget deleted successfully"})
+}
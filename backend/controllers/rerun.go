@@ -0,0 +1,81 @@
+package controllers
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"qa-automation-system/backend/models"
+	"qa-automation-system/backend/pkg/testrunner"
+)
+
+// rerunCredentials returns the credentials of a rerun: the email and password in the request body,
+// otherwise the account of the environment when the original run logged in with it. Passwords are
+// not stored, so a run with custom credentials can only be rerun by passing them again.
+func rerunCredentials(ctx *gin.Context, original models.Run) (string, string, string, error) {
+	var payload struct {
+		Email    string `json:"email"`
+		Password string `json:"password"`
+	}
+	if ctx.Request.ContentLength > 0 {
+		if err := ctx.ShouldBindJSON(&payload); err != nil {
+			return "", "", "", err
+		}
+	}
+
+	if payload.Email != "" && payload.Password != "" {
+		return payload.Email, payload.Password, models.CredentialsCustom, nil
+	}
+	if original.CredentialsRef == models.CredentialsCustom {
+		return "", "", "", fmt.Errorf("the original run logged in as %s, pass its email and password to rerun it", original.Email)
+	}
+
+	return os.Getenv("SENTI_EMAIL"), os.Getenv("SENTI_PASSWORD"), models.CredentialsEnv, nil
+}
+
+// startRerun creates a run repeating the results on their browsers with the settings of the original
+// run, and starts it in the background. The new results link to the ones they repeat.
+func startRerun(db *gorm.DB, original models.Run, results []models.Result, email, password, credentialsRef string) (models.Run, error) {
+	run := models.Run{
+		SiteID:         original.SiteID,
+		DeviceID:       original.DeviceID,
+		SingleSession:  original.SingleSession,
+		Retry:          original.Retry,
+		CaptureHAR:     original.CaptureHAR,
+		Email:          email,
+		CredentialsRef: credentialsRef,
+	}
+	if original.ID != 0 {
+		run.RerunOfID = &original.ID
+	}
+	if err := db.Omit("Site", "Device").Create(&run).Error; err != nil {
+		return run, err
+	}
+
+	// Keep the features and browsers in the order they were first run in
+	var featureIDs []uint
+	var browsers []string
+	seenFeatures := make(map[uint]bool)
+	seenBrowsers := make(map[string]bool)
+	rerunOf := make(map[string]uint)
+	for _, result := range results {
+		if !seenFeatures[result.FeatureID] {
+			seenFeatures[result.FeatureID] = true
+			featureIDs = append(featureIDs, result.FeatureID)
+		}
+		if !seenBrowsers[result.Browser] {
+			seenBrowsers[result.Browser] = true
+			browsers = append(browsers, result.Browser)
+		}
+		rerunOf[testrunner.RerunKey(result.FeatureID, result.Browser)] = result.ID
+	}
+
+	go testrunner.RunTestInBackground(run.ID, featureIDs, email, password, testrunner.RunOptions{
+		CaptureHAR: run.CaptureHAR,
+		Browsers:   browsers,
+		RerunOf:    rerunOf,
+	})
+
+	return run, nil
+}
@@ -6,10 +6,8 @@ import (
 	"net/http"
 	"os"
 	"strconv"
-	"time"
 
 	"github.com/gin-gonic/gin"
-	"github.com/xuri/excelize/v2"
 	"gorm.io/gorm"
 	"qa-automation-system/backend/config"
 	"qa-automation-system/backend/models"
@@ -37,14 +35,20 @@ func NewResultController(db *gorm.DB) *ResultController {
 	return &ResultController{DB: db}
 }
 
-// Create handles the creation of a new result
+// Create handles the creation of a new result. Every submission creates a run holding
+// the results of its features; with single_session the features share one browser session,
+// and retry overrides the retry policies of the features.
 func (c *ResultController) Create(ctx *gin.Context) {
 	var payload struct {
 		SiteID    uint `json:"site_id" binding:"required"`
 		DeviceID  uint `json:"device_id" binding:"required"`
-		FeatureID uint `json:"feature_id" binding:"required"`
+		FeatureID uint `json:"feature_id"`
+		FeatureIDs []uint `json:"feature_ids"`
+		SingleSession bool `json:"single_session"`
+		Retry     *models.RetryPolicy `json:"retry"`
 		Email     string `json:"email"`
 		Password  string `json:"password"`
+		CaptureHAR bool  `json:"capture_har"`
 	}
 
 	if err := ctx.ShouldBindJSON(&payload); err != nil {
@@ -52,20 +56,86 @@ func (c *ResultController) Create(ctx *gin.Context) {
 		return
 	}
 
+	// The features run in the given order, feature_id first
+	featureIDs := payload.FeatureIDs
+	if payload.FeatureID != 0 {
+		featureIDs = append([]uint{payload.FeatureID}, featureIDs...)
+	}
+	if len(featureIDs) == 0 {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "feature_id or feature_ids is required"})
+		return
+	}
+
+	// A run retry policy overrides the ones of its features
+	var retry models.RetryPolicy
+	if payload.Retry != nil {
+		if err := validateRetryPolicy(*payload.Retry); err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		retry = *payload.Retry
+	}
+
+	var count int64
+	if err := c.DB.Model(&models.Site{}).Where("id = ?", payload.SiteID).Count(&count).Error; err != nil || count == 0 {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Site not found"})
+		return
+	}
+	if err := c.DB.Model(&models.Device{}).Where("id = ?", payload.DeviceID).Count(&count).Error; err != nil || count == 0 {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Device not found"})
+		return
+	}
+
+	// Every feature must exist, otherwise the run would silently test nothing for it
+	var found []uint
+	if err := c.DB.Model(&models.Feature{}).Where("id IN ?", featureIDs).Pluck("id", &found).Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	known := make(map[uint]bool, len(found))
+	for _, id := range found {
+		known[id] = true
+	}
+	for _, id := range featureIDs {
+		if !known[id] {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": fmt.Sprintf("Feature %d not found", id)})
+			return
+		}
+	}
+
 	// Get credentials from environment variables
 	email := os.Getenv("SENTI_EMAIL")
 	password := os.Getenv("SENTI_PASSWORD")
+	credentialsRef := models.CredentialsEnv
 
 	if payload.Email != "" && payload.Password != "" {
 		email = payload.Email
 		password = payload.Password
+		credentialsRef = models.CredentialsCustom
+	}
+
+	run := models.Run{
+		SiteID:        payload.SiteID,
+		DeviceID:      payload.DeviceID,
+		SingleSession: payload.SingleSession,
+		Retry:         retry,
+		CaptureHAR:    payload.CaptureHAR,
+		Email:         email,
+		CredentialsRef: credentialsRef,
+	}
+	if err := c.DB.Omit("Site", "Device").Create(&run).Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
 	}
 
 	// Run the test in the background with the payload
-	go testrunner.RunTestInBackground(payload.SiteID, payload.DeviceID, payload.FeatureID, email, password)
+	go testrunner.RunTestInBackground(run.ID, featureIDs, email, password, testrunner.RunOptions{
+		CaptureHAR: payload.CaptureHAR,
+	})
 
 	ctx.JSON(http.StatusOK, gin.H{
 		"message": "Test started in background",
+		"run_id":  run.ID,
 		"payload": payload,
 	})
 }
@@ -90,7 +160,9 @@ func (c *ResultController) GetByID(ctx *gin.Context) {
 	}
 
 	var result models.Result
-	if err := c.DB.Preload("Site").Preload("Device").Preload("Feature").Preload("Details").First(&result, id).Error; err != nil {
+	if err := c.DB.Preload("Site").Preload("Device").Preload("Feature").Preload("Details").Preload("Artifacts").Preload("PageMetrics").Preload("Metrics").Preload("Findings").Preload("Hooks").Preload("Comments", func(db *gorm.DB) *gorm.DB {
+		return db.Order("id ASC")
+	}).First(&result, id).Error; err != nil {
 		ctx.JSON(http.StatusNotFound, gin.H{"error": "Result not found"})
 		return
 	}
@@ -125,6 +197,53 @@ func (c *ResultController) Update(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, result)
 }
 
+// Rerun runs the feature of a result again on the same site, device and browser, with the
+// settings and credentials of its run
+func (c *ResultController) Rerun(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
+	}
+
+	var result models.Result
+	if err := c.DB.First(&result, id).Error; err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Result not found"})
+		return
+	}
+	if result.Browser == "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Result has no browser to rerun on"})
+		return
+	}
+
+	// Results from before runs existed are rerun with the default settings
+	original := models.Run{SiteID: result.SiteID, DeviceID: result.DeviceID, CredentialsRef: models.CredentialsEnv}
+	if result.RunID != nil {
+		if err := c.DB.First(&original, *result.RunID).Error; err != nil {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "Run not found"})
+			return
+		}
+	}
+
+	email, password, credentialsRef, err := rerunCredentials(ctx, original)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	run, err := startRerun(c.DB, original, []models.Result{result}, email, password, credentialsRef)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message":     "Rerun started in background",
+		"run_id":      run.ID,
+		"rerun_of_id": result.ID,
+	})
+}
+
 // Delete handles deleting a result
 func (c *ResultController) Delete(ctx *gin.Context) {
 	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
@@ -223,34 +342,16 @@ func (rc *ResultController) GetResults(c *gin.Context) {
 	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
 	offset := (page - 1) * limit
 
-	// Get filter parameters
-	siteID := c.Query("site_id")
-	deviceID := c.Query("device_id")
-	featureID := c.Query("feature_id")
-	status := c.Query("status")
-
 	var results []models.Result
 	var total int64
-	query := rc.DB.Model(&models.Result{})
-	countQuery := rc.DB.Model(&models.Result{})
 
 	// Apply filters if they exist
-	if siteID != "" {
-		query = query.Where("site_id = ?", siteID)
-		countQuery = countQuery.Where("site_id = ?", siteID)
-	}
-	if deviceID != "" {
-		query = query.Where("device_id = ?", deviceID)
-		countQuery = countQuery.Where("device_id = ?", deviceID)
-	}
-	if featureID != "" {
-		query = query.Where("feature_id = ?", featureID)
-		countQuery = countQuery.Where("feature_id = ?", featureID)
-	}
-	if status != "" {
-		query = query.Where("status = ?", status)
-		countQuery = countQuery.Where("status = ?", status)
+	query, err := filterResults(rc.DB.Model(&models.Result{}), c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid date, expected YYYY-MM-DD"})
+		return
 	}
+	countQuery, _ := filterResults(rc.DB.Model(&models.Result{}), c)
 
 	// Get total count with filters
 	if err := query.Count(&total).Error; err != nil {
@@ -264,6 +365,7 @@ func (rc *ResultController) GetResults(c *gin.Context) {
 		Preload("Device").
 		Preload("Feature").
 		Preload("Details").
+		Preload("Artifacts").
 		Order("created_at DESC").
 		Offset(offset).
 		Limit(limit).
@@ -282,6 +384,17 @@ func (rc *ResultController) GetResults(c *gin.Context) {
 		return
 	}
 
+	// Get failed counts by failure category
+	var failureCategoryCounts []struct {
+		FailureCategory string `json:"failure_category"`
+		Count           int64  `json:"count"`
+	}
+	categoryQuery, _ := filterResults(rc.DB.Model(&models.Result{}), c)
+	if err := categoryQuery.Select("COALESCE(failure_category, '') AS failure_category, COUNT(*) as count").Where("status = ?", "failed").Group("failure_category").Scan(&failureCategoryCounts).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to count failure categories"})
+		return
+	}
+
 	c.JSON(http.StatusOK, gin.H{
 		"data": results,
 		"meta": gin.H{
@@ -290,6 +403,7 @@ func (rc *ResultController) GetResults(c *gin.Context) {
 			"limit": limit,
 			"total_pages": int(math.Ceil(float64(total) / float64(limit))),
 			"status_counts": statusCounts,
+			"failure_category_counts": failureCategoryCounts,
 		},
 	})
 }
@@ -362,152 +476,32 @@ func (rc *ResultController) DeleteResultDetail(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "Result detail deleted successfully"})
 }
 
-// ExportResults exports test results to Excel
-func (rc *ResultController) ExportResults(c *gin.Context) {
-	var results []models.Result
-	if err := rc.DB.Preload("Site").Preload("Device").Preload("Feature").Order("created_at DESC").Find(&results).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		return
-	}
-
-	fmt.Printf("Found %d results to export\n", len(results))
-
-	// Create new Excel file
-	f := excelize.NewFile()
-	defer func() {
-		if err := f.Close(); err != nil {
-			fmt.Println(err)
-		}
-	}()
-
-	// Create a new sheet
-	sheet := "Test Results"
-	index, err := f.NewSheet(sheet)
+// GetNetworkSummary handles GET request to summarize the failed requests of a result's HAR capture
+func (rc *ResultController) GetNetworkSummary(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid result ID"})
 		return
 	}
-	f.SetActiveSheet(index)
-	f.DeleteSheet("Sheet1") // Delete default sheet
 
-	// Create headers
-	headers := []string{
-		"Created At",
-		"Status",
-		"Site Name",
-		"Browser",
-		"Device Name",
-		"Feature Name",
-		"Duration (s)",
-		"Error Log",
+	var artifact models.ResultArtifact
+	if err := rc.DB.Where("result_id = ? AND type = ?", uint(id), models.ArtifactTypeHAR).Order("id DESC").First(&artifact).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "HAR capture not found"})
+		return
 	}
 
-	// Set headers with style
-	style, err := f.NewStyle(&excelize.Style{
-		Font: &excelize.Font{
-			Bold: true,
-			Size: 12,
-		},
-		Fill: excelize.Fill{
-			Type:    "pattern",
-			Color:   []string{"#E0E0E0"},
-			Pattern: 1,
-		},
-		Alignment: &excelize.Alignment{
-			Horizontal: "center",
-			Vertical:   "center",
-		},
-	})
+	har, err := testrunner.LoadHAR(artifact.Path)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
-	// Set headers
-	for i, header := range headers {
-		cell := fmt.Sprintf("%c1", 'A'+i)
-		if err := f.SetCellValue(sheet, cell, header); err != nil {
-			fmt.Printf("Error setting header %s: %v\n", header, err)
-		}
-		if err := f.SetCellStyle(sheet, cell, cell, style); err != nil {
-			fmt.Printf("Error setting style for header %s: %v\n", header, err)
-		}
-	}
-
-	// Add data
-	for i, result := range results {
-		row := i + 2
-		fmt.Printf("Processing result %d: ID=%d, Status=%s\n", i+1, result.ID, result.Status)
-
-		// Format created_at
-		createdAt := result.CreatedAt.Format("2006-01-02 15:04:05")
-		
-		// Get related data
-		siteName := "N/A"
-		if result.SiteID != 0 {
-			siteName = result.Site.Name
-		}
-
-		deviceName := "N/A"
-		if result.DeviceID != 0 {
-			deviceName = result.Device.Name
-		}
-
-		featureName := "N/A"
-		if result.FeatureID != 0 {
-			featureName = result.Feature.Name
-		}
-
-		// Set values
-		cells := map[string]interface{}{
-			fmt.Sprintf("A%d", row): createdAt,
-			fmt.Sprintf("B%d", row): result.Status,
-			fmt.Sprintf("C%d", row): siteName,
-			fmt.Sprintf("D%d", row): result.Browser,
-			fmt.Sprintf("E%d", row): deviceName,
-			fmt.Sprintf("F%d", row): featureName,
-			fmt.Sprintf("G%d", row): result.Duration,
-			fmt.Sprintf("H%d", row): result.ErrorLog,
-		}
-
-		for cell, value := range cells {
-			if err := f.SetCellValue(sheet, cell, value); err != nil {
-				fmt.Printf("Error setting cell %s: %v\n", cell, err)
-			}
-		}
-	}
-
-	// Set column widths
-	widths := map[string]float64{
-		"A": 20, // Created At
-		"B": 10, // Status
-		"C": 20, // Site Name
-		"D": 15, // Browser
-		"E": 20, // Device Name
-		"F": 20, // Feature Name
-		"G": 15, // Duration
-		"H": 50, // Error Log
-	}
-
-	for col, width := range widths {
-		if err := f.SetColWidth(sheet, col, col, width); err != nil {
-			fmt.Printf("Error setting column width for %s: %v\n", col, err)
-		}
-	}
-
-	// Generate filename with timestamp
-	filename := fmt.Sprintf("test_results_%s.xlsx", time.Now().Format("20060102_150405"))
+	failedRequests := testrunner.FailedRequests(har)
 
-	// Set response headers
-	c.Header("Content-Type", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet")
-	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%s", filename))
-
-	// Write file to response
-	if err := f.Write(c.Writer); err != nil {
-		fmt.Printf("Error writing file: %v\n", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		return
-	}
-
-	fmt.Println("Export completed successfully")
+	c.JSON(http.StatusOK, gin.H{
+		"artifact":        artifact,
+		"total_requests":  len(har.Log.Entries),
+		"failed_count":    len(failedRequests),
+		"failed_requests": failedRequests,
+	})
 } 
\ No newline at end of file
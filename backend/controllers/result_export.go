@@ -0,0 +1,392 @@
+package controllers
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"qa-automation-system/backend/models"
+	"qa-automation-system/backend/pkg/report"
+)
+
+// exportHeaders are the column titles of the CSV and XLSX exports
+var exportHeaders = []string{
+	"Created At",
+	"Status",
+	"Site Name",
+	"Browser",
+	"Device Name",
+	"Feature Name",
+	"Duration (s)",
+	"Error Log",
+	"Failure Category",
+}
+
+// exportRow is a result as written to an export
+type exportRow struct {
+	ID              uint      `json:"id"`
+	CreatedAt       time.Time `json:"created_at"`
+	Status          string    `json:"status"`
+	SiteName        string    `json:"site_name"`
+	Browser         string    `json:"browser"`
+	DeviceName      string    `json:"device_name"`
+	FeatureName     string    `json:"feature_name"`
+	Duration        float64   `json:"duration"`
+	ErrorLog        string    `json:"error_log"`
+	FailureCategory string    `json:"failure_category"`
+}
+
+// values returns the row in the column order of exportHeaders
+func (r exportRow) values() []interface{} {
+	return []interface{}{
+		r.CreatedAt.Format("2006-01-02 15:04:05"),
+		r.Status,
+		orNA(r.SiteName),
+		r.Browser,
+		orNA(r.DeviceName),
+		orNA(r.FeatureName),
+		r.Duration,
+		r.ErrorLog,
+		r.FailureCategory,
+	}
+}
+
+// orNA returns N/A for a missing related name
+func orNA(value string) string {
+	if value == "" {
+		return "N/A"
+	}
+	return value
+}
+
+// filterResults applies the site_id, device_id, feature_id, status, failure_category and triage_status
+// (comma-separated), assignee, bug_url and from/to (YYYY-MM-DD, inclusive) query filters to a results query
+func filterResults(query *gorm.DB, c *gin.Context) (*gorm.DB, error) {
+	if siteID := c.Query("site_id"); siteID != "" {
+		query = query.Where("results.site_id = ?", siteID)
+	}
+	if deviceID := c.Query("device_id"); deviceID != "" {
+		query = query.Where("results.device_id = ?", deviceID)
+	}
+	if featureID := c.Query("feature_id"); featureID != "" {
+		query = query.Where("results.feature_id = ?", featureID)
+	}
+	if status := c.Query("status"); status != "" {
+		query = query.Where("results.status = ?", status)
+	}
+	if category := c.Query("failure_category"); category != "" {
+		query = query.Where("results.failure_category IN ?", strings.Split(category, ","))
+	}
+	if triageStatus := c.Query("triage_status"); triageStatus != "" {
+		query = query.Where("results.triage_status IN ?", strings.Split(triageStatus, ","))
+	}
+	if assignee := c.Query("assignee"); assignee != "" {
+		query = query.Where("results.assignee = ?", assignee)
+	}
+	if bugURL := c.Query("bug_url"); bugURL != "" {
+		query = query.Where("results.bug_url = ?", bugURL)
+	}
+	if value := c.Query("from"); value != "" {
+		from, err := time.ParseInLocation("2006-01-02", value, time.Local)
+		if err != nil {
+			return nil, err
+		}
+		query = query.Where("results.created_at >= ?", from)
+	}
+	if value := c.Query("to"); value != "" {
+		to, err := time.ParseInLocation("2006-01-02", value, time.Local)
+		if err != nil {
+			return nil, err
+		}
+		query = query.Where("results.created_at < ?", to.AddDate(0, 0, 1))
+	}
+
+	return query, nil
+}
+
+// exportQuery selects the filtered results with the names of their site, device and feature
+func (rc *ResultController) exportQuery(c *gin.Context) (*gorm.DB, error) {
+	query := rc.DB.Table("results").
+		Select("results.id, results.created_at, results.status, sites.name AS site_name, results.browser, " +
+			"devices.name AS device_name, features.name AS feature_name, results.duration, results.error_log, " +
+			"COALESCE(results.failure_category, '') AS failure_category").
+		Joins("LEFT JOIN sites ON sites.id = results.site_id").
+		Joins("LEFT JOIN devices ON devices.id = results.device_id").
+		Joins("LEFT JOIN features ON features.id = results.feature_id").
+		Order("results.created_at DESC")
+
+	return filterResults(query, c)
+}
+
+// streamResults calls fn for each exported row, reading them from the database one at a time
+func (rc *ResultController) streamResults(query *gorm.DB, fn func(row exportRow) error) error {
+	rows, err := query.Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var row exportRow
+		if err := rc.DB.ScanRows(rows, &row); err != nil {
+			return err
+		}
+		if err := fn(row); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
+// ExportResults streams the filtered test results as format=xlsx (default), csv, json or junit
+func (rc *ResultController) ExportResults(c *gin.Context) {
+	format := c.DefaultQuery("format", "xlsx")
+	if format != "xlsx" && format != "csv" && format != "json" && format != "junit" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid format, expected csv, json, junit or xlsx"})
+		return
+	}
+
+	query, err := rc.exportQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid date, expected YYYY-MM-DD"})
+		return
+	}
+
+	// Generate filename with timestamp
+	extension := format
+	if format == "junit" {
+		extension = "xml"
+	}
+	filename := fmt.Sprintf("test_results_%s.%s", time.Now().Format("20060102_150405"), extension)
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%s", filename))
+
+	switch format {
+	case "junit":
+		err = rc.exportJUnit(c)
+	case "csv":
+		err = rc.exportCSV(c, query)
+	case "json":
+		err = rc.exportJSON(c, query)
+	default:
+		err = rc.exportXLSX(c, query)
+	}
+
+	// Once rows are streamed the status is sent, the error can only be logged
+	if err != nil {
+		fmt.Printf("Error exporting results: %v\n", err)
+		if !c.Writer.Written() {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+	}
+}
+
+// exportCSV writes the rows as CSV, flushing to the client as they are read
+func (rc *ResultController) exportCSV(c *gin.Context, query *gorm.DB) error {
+	c.Header("Content-Type", "text/csv")
+	writer := csv.NewWriter(c.Writer)
+
+	if err := writer.Write(exportHeaders); err != nil {
+		return err
+	}
+
+	count := 0
+	err := rc.streamResults(query, func(row exportRow) error {
+		record := make([]string, 0, len(exportHeaders))
+		for _, value := range row.values() {
+			switch v := value.(type) {
+			case float64:
+				record = append(record, strconv.FormatFloat(v, 'f', -1, 64))
+			default:
+				record = append(record, fmt.Sprintf("%v", v))
+			}
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+
+		count++
+		if count%500 == 0 {
+			writer.Flush()
+			c.Writer.Flush()
+		}
+		return writer.Error()
+	})
+	if err != nil {
+		return err
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// exportJSON writes the rows as a JSON array, one element at a time
+func (rc *ResultController) exportJSON(c *gin.Context, query *gorm.DB) error {
+	c.Header("Content-Type", "application/json")
+	if _, err := c.Writer.WriteString("["); err != nil {
+		return err
+	}
+
+	count := 0
+	err := rc.streamResults(query, func(row exportRow) error {
+		data, err := json.Marshal(row)
+		if err != nil {
+			return err
+		}
+		if count > 0 {
+			if _, err := c.Writer.WriteString(","); err != nil {
+				return err
+			}
+		}
+		if _, err := c.Writer.Write(data); err != nil {
+			return err
+		}
+
+		count++
+		if count%500 == 0 {
+			c.Writer.Flush()
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = c.Writer.WriteString("]")
+	return err
+}
+
+// junitBatchSize is the number of results a JUnit export loads with their details at once
+const junitBatchSize = 200
+
+// exportJUnit writes the filtered results newest first as a JUnit XML report, loading them in batches
+func (rc *ResultController) exportJUnit(c *gin.Context) error {
+	// The suite totals come first in the document, so count them beforehand
+	var totals struct {
+		Tests    int
+		Failures int
+		Skipped  int
+		Time     float64
+	}
+	countQuery, err := filterResults(rc.DB.Model(&models.Result{}), c)
+	if err != nil {
+		return err
+	}
+	if err := countQuery.Select("COUNT(*) AS tests, " +
+		"COALESCE(SUM(CASE WHEN results.status = 'failed' THEN 1 ELSE 0 END), 0) AS failures, " +
+		"COALESCE(SUM(CASE WHEN results.status = 'processing' THEN 1 ELSE 0 END), 0) AS skipped, " +
+		"COALESCE(SUM(results.duration), 0) AS time").Scan(&totals).Error; err != nil {
+		return err
+	}
+
+	query, err := filterResults(rc.DB.Model(&models.Result{}), c)
+	if err != nil {
+		return err
+	}
+	query = query.Preload("Site").Preload("Device").Preload("Feature").Preload("Details", func(db *gorm.DB) *gorm.DB {
+		return db.Order("id ASC")
+	}).Order("results.created_at DESC, results.id DESC").Limit(junitBatchSize).Session(&gorm.Session{})
+
+	c.Header("Content-Type", "application/xml")
+	writer, err := report.NewJUnitWriter(c.Writer, "Test Results", report.JUnitTotals(totals))
+	if err != nil {
+		return err
+	}
+
+	// Newest first like the other formats, in batches that continue after the last result written
+	batchQuery := query
+	for {
+		var batch []models.Result
+		if err := batchQuery.Find(&batch).Error; err != nil {
+			return err
+		}
+		for _, result := range batch {
+			if err := writer.WriteTestCase(report.NewJUnitTestCase(result)); err != nil {
+				return err
+			}
+		}
+		c.Writer.Flush()
+
+		if len(batch) < junitBatchSize {
+			break
+		}
+		last := batch[len(batch)-1]
+		batchQuery = query.Where("(results.created_at < ? OR (results.created_at = ? AND results.id < ?))",
+			last.CreatedAt, last.CreatedAt, last.ID)
+	}
+
+	return writer.Close()
+}
+
+// maxReportResults caps the results of an HTML report, which holds them and their screenshots in one page
+const maxReportResults = 500
+
+// requestBaseURL returns the scheme and host the API was called on, for links to its static files
+func requestBaseURL(c *gin.Context) string {
+	scheme := "http"
+	if c.Request.TLS != nil || c.GetHeader("X-Forwarded-Proto") == "https" {
+		scheme = "https"
+	}
+	return scheme + "://" + c.Request.Host
+}
+
+// htmlOptions reads how an HTML report references screenshots from screenshots=embed (default) or link
+func htmlOptions(c *gin.Context) (report.HTMLOptions, error) {
+	options := report.HTMLOptions{BaseURL: requestBaseURL(c)}
+
+	switch c.DefaultQuery("screenshots", "embed") {
+	case "embed":
+		options.EmbedScreenshots = true
+	case "link":
+	default:
+		return options, fmt.Errorf("Invalid screenshots, expected embed or link")
+	}
+
+	return options, nil
+}
+
+// ReportResults returns the filtered results, newest first and at most limit (default 100), as a
+// self-contained HTML report
+func (rc *ResultController) ReportResults(c *gin.Context) {
+	options, err := htmlOptions(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "100"))
+	if err != nil || limit < 1 || limit > maxReportResults {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Invalid limit, expected 1 to %d", maxReportResults)})
+		return
+	}
+
+	query, err := filterResults(rc.DB.Model(&models.Result{}), c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid date, expected YYYY-MM-DD"})
+		return
+	}
+
+	var results []models.Result
+	if err := query.Preload("Site").Preload("Device").Preload("Feature").Preload("Details", func(db *gorm.DB) *gorm.DB {
+		return db.Order("id ASC")
+	}).Order("results.created_at DESC").Limit(limit).Find(&results).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	htmlReport := report.NewHTMLReport("Test Results", results, options)
+
+	filename := fmt.Sprintf("test_results_%s.html", time.Now().Format("20060102_150405"))
+	c.Header("Content-Type", "text/html; charset=utf-8")
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%s", filename))
+	c.Status(http.StatusOK)
+	if err := report.WriteHTML(c.Writer, htmlReport); err != nil {
+		fmt.Printf("Error writing HTML report: %v\n", err)
+	}
+}
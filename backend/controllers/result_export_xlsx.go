@@ -0,0 +1,431 @@
+package controllers
+
+import (
+	"fmt"
+	"image"
+	_ "image/jpeg"
+	_ "image/png"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xuri/excelize/v2"
+	"gorm.io/gorm"
+	"qa-automation-system/backend/pkg/report"
+)
+
+// maxExportPictures caps the screenshots embedded in an XLSX export, the steps after it link to theirs
+const maxExportPictures = 200
+
+// thumbnailRowHeight is the height in points of a Steps row holding a screenshot, and
+// thumbnailHeight the height in pixels of the screenshot, which leaves it a margin in the row
+const (
+	thumbnailRowHeight = 120
+	thumbnailHeight    = 120
+)
+
+// summaryHeaders are the column titles of the Summary sheet
+var summaryHeaders = []string{"Site Name", "Feature Name", "Browser", "Total", "Passed", "Warning", "Failed", "Processing", "Pass Rate"}
+
+// stepHeaders are the column titles of the Steps sheet
+var stepHeaders = []string{"Result", "Created At", "Site Name", "Device Name", "Browser", "Feature Name", "Step", "Screenshot"}
+
+// summaryRow is the outcome counts of a site, feature and browser
+type summaryRow struct {
+	SiteName    string
+	FeatureName string
+	Browser     string
+	Total       int
+	Passed      int
+	Warning     int
+	Failed      int
+	Processing  int
+}
+
+// stepRow is a step of a result as written to the Steps sheet
+type stepRow struct {
+	ResultID    uint
+	CreatedAt   time.Time
+	SiteName    string
+	DeviceName  string
+	Browser     string
+	FeatureName string
+	Description string
+	Screenshot  string
+}
+
+// xlsxStyles are the cell styles shared by the sheets of an export
+type xlsxStyles struct {
+	header  int
+	link    int
+	percent int
+	total   int
+	wrap    int
+}
+
+// dashboardResultURL returns the page of a result on the dashboard at DASHBOARD_URL
+func dashboardResultURL(id uint) string {
+	base := os.Getenv("DASHBOARD_URL")
+	if base == "" {
+		base = "http://localhost:3000"
+	}
+	return fmt.Sprintf("%s/results/%d", strings.TrimSuffix(base, "/"), id)
+}
+
+// newXLSXStyles registers the styles of an export in the workbook
+func newXLSXStyles(f *excelize.File) (xlsxStyles, error) {
+	var styles xlsxStyles
+	var err error
+
+	styles.header, err = f.NewStyle(&excelize.Style{
+		Font: &excelize.Font{
+			Bold: true,
+			Size: 12,
+		},
+		Fill: excelize.Fill{
+			Type:    "pattern",
+			Color:   []string{"#E0E0E0"},
+			Pattern: 1,
+		},
+		Alignment: &excelize.Alignment{
+			Horizontal: "center",
+			Vertical:   "center",
+		},
+	})
+	if err != nil {
+		return styles, err
+	}
+
+	styles.link, err = f.NewStyle(&excelize.Style{
+		Font: &excelize.Font{Color: "#1265BE", Underline: "single"},
+	})
+	if err != nil {
+		return styles, err
+	}
+
+	styles.percent, err = f.NewStyle(&excelize.Style{NumFmt: 10})
+	if err != nil {
+		return styles, err
+	}
+
+	styles.total, err = f.NewStyle(&excelize.Style{Font: &excelize.Font{Bold: true}})
+	if err != nil {
+		return styles, err
+	}
+
+	styles.wrap, err = f.NewStyle(&excelize.Style{
+		Alignment: &excelize.Alignment{Vertical: "top", WrapText: true},
+	})
+	return styles, err
+}
+
+// setHeader writes the column titles and widths of a sheet in normal mode
+func setHeader(f *excelize.File, sheet string, headers []string, widths []float64, style int) error {
+	for i, title := range headers {
+		cell, err := excelize.CoordinatesToCellName(i+1, 1)
+		if err != nil {
+			return err
+		}
+		if err := f.SetCellValue(sheet, cell, title); err != nil {
+			return err
+		}
+		column, err := excelize.ColumnNumberToName(i + 1)
+		if err != nil {
+			return err
+		}
+		if err := f.SetColWidth(sheet, column, column, widths[i]); err != nil {
+			return err
+		}
+	}
+
+	lastCell, err := excelize.CoordinatesToCellName(len(headers), 1)
+	if err != nil {
+		return err
+	}
+	if err := f.SetCellStyle(sheet, "A1", lastCell, style); err != nil {
+		return err
+	}
+
+	return f.SetPanes(sheet, &excelize.Panes{Freeze: true, YSplit: 1, TopLeftCell: "A2", ActivePane: "bottomLeft"})
+}
+
+// exportXLSX writes the filtered results to a workbook with a Summary sheet of the outcomes by site,
+// feature and browser, a Test Results sheet with a row per result and a Steps sheet with a row per
+// step and its screenshot
+func (rc *ResultController) exportXLSX(c *gin.Context, query *gorm.DB) error {
+	// Create new Excel file
+	f := excelize.NewFile()
+	defer func() {
+		if err := f.Close(); err != nil {
+			fmt.Println(err)
+		}
+	}()
+
+	styles, err := newXLSXStyles(f)
+	if err != nil {
+		return err
+	}
+
+	if err := f.SetSheetName("Sheet1", "Summary"); err != nil {
+		return err
+	}
+	for _, sheet := range []string{"Test Results", "Steps"} {
+		if _, err := f.NewSheet(sheet); err != nil {
+			return err
+		}
+	}
+
+	if err := rc.writeSummarySheet(c, f, "Summary", styles); err != nil {
+		return err
+	}
+	if err := rc.writeResultsSheet(f, "Test Results", query, styles); err != nil {
+		return err
+	}
+	if err := rc.writeStepsSheet(c, f, "Steps", styles); err != nil {
+		return err
+	}
+	f.SetActiveSheet(0)
+
+	// Set response headers
+	c.Header("Content-Type", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet")
+
+	// Write file to response
+	return f.Write(c.Writer)
+}
+
+// writeSummarySheet writes the outcome counts of each site, feature and browser, with a total row
+func (rc *ResultController) writeSummarySheet(c *gin.Context, f *excelize.File, sheet string, styles xlsxStyles) error {
+	query, err := filterResults(rc.DB.Table("results"), c)
+	if err != nil {
+		return err
+	}
+
+	var rows []summaryRow
+	if err := query.Select("COALESCE(sites.name, 'N/A') AS site_name, COALESCE(features.name, 'N/A') AS feature_name, " +
+		"COALESCE(results.browser, '') AS browser, COUNT(*) AS total, " +
+		"SUM(CASE WHEN results.status = 'passed' THEN 1 ELSE 0 END) AS passed, " +
+		"SUM(CASE WHEN results.status = 'warning' THEN 1 ELSE 0 END) AS warning, " +
+		"SUM(CASE WHEN results.status = 'failed' THEN 1 ELSE 0 END) AS failed, " +
+		"SUM(CASE WHEN results.status = 'processing' THEN 1 ELSE 0 END) AS processing").
+		Joins("LEFT JOIN sites ON sites.id = results.site_id").
+		Joins("LEFT JOIN features ON features.id = results.feature_id").
+		Group("sites.name, features.name, results.browser").
+		Order("sites.name, features.name, results.browser").
+		Scan(&rows).Error; err != nil {
+		return err
+	}
+
+	if err := setHeader(f, sheet, summaryHeaders, []float64{20, 25, 15, 10, 10, 10, 10, 12, 12}, styles.header); err != nil {
+		return err
+	}
+
+	for i, row := range rows {
+		number := i + 2
+		values := []interface{}{row.SiteName, row.FeatureName, row.Browser, row.Total, row.Passed, row.Warning, row.Failed, row.Processing}
+		if err := f.SetSheetRow(sheet, fmt.Sprintf("A%d", number), &values); err != nil {
+			return err
+		}
+		if err := f.SetCellFormula(sheet, fmt.Sprintf("I%d", number), fmt.Sprintf("IF(D%d=0,0,E%d/D%d)", number, number, number)); err != nil {
+			return err
+		}
+		if err := f.SetCellStyle(sheet, fmt.Sprintf("I%d", number), fmt.Sprintf("I%d", number), styles.percent); err != nil {
+			return err
+		}
+	}
+
+	// Totals of the count columns
+	total := len(rows) + 2
+	if err := f.SetCellValue(sheet, fmt.Sprintf("A%d", total), "Total"); err != nil {
+		return err
+	}
+	for _, column := range []string{"D", "E", "F", "G", "H"} {
+		formula := "0"
+		if len(rows) > 0 {
+			formula = fmt.Sprintf("SUM(%s2:%s%d)", column, column, total-1)
+		}
+		if err := f.SetCellFormula(sheet, fmt.Sprintf("%s%d", column, total), formula); err != nil {
+			return err
+		}
+	}
+	if err := f.SetCellFormula(sheet, fmt.Sprintf("I%d", total), fmt.Sprintf("IF(D%d=0,0,E%d/D%d)", total, total, total)); err != nil {
+		return err
+	}
+	if err := f.SetCellStyle(sheet, fmt.Sprintf("A%d", total), fmt.Sprintf("H%d", total), styles.total); err != nil {
+		return err
+	}
+	return f.SetCellStyle(sheet, fmt.Sprintf("I%d", total), fmt.Sprintf("I%d", total), styles.percent)
+}
+
+// writeResultsSheet writes a row per result with the excelize stream writer, which keeps memory use
+// flat by spilling rows to a temporary file. The last column links to the result on the dashboard.
+func (rc *ResultController) writeResultsSheet(f *excelize.File, sheet string, query *gorm.DB, styles xlsxStyles) error {
+	sw, err := f.NewStreamWriter(sheet)
+	if err != nil {
+		return err
+	}
+
+	// Set column widths, which the stream writer requires before any row
+	widths := []float64{
+		20, // Created At
+		10, // Status
+		20, // Site Name
+		15, // Browser
+		20, // Device Name
+		20, // Feature Name
+		15, // Duration
+		50, // Error Log
+		20, // Failure Category
+		12, // Result
+	}
+	for i, width := range widths {
+		if err := sw.SetColWidth(i+1, i+1, width); err != nil {
+			return err
+		}
+	}
+	if err := sw.SetPanes(&excelize.Panes{Freeze: true, YSplit: 1, TopLeftCell: "A2", ActivePane: "bottomLeft"}); err != nil {
+		return err
+	}
+
+	header := make([]interface{}, 0, len(exportHeaders)+1)
+	for _, title := range exportHeaders {
+		header = append(header, excelize.Cell{StyleID: styles.header, Value: title})
+	}
+	header = append(header, excelize.Cell{StyleID: styles.header, Value: "Result"})
+	if err := sw.SetRow("A1", header); err != nil {
+		return err
+	}
+
+	// Add data
+	rowNumber := 1
+	err = rc.streamResults(query, func(row exportRow) error {
+		rowNumber++
+		cell, err := excelize.CoordinatesToCellName(1, rowNumber)
+		if err != nil {
+			return err
+		}
+		link := excelize.Cell{
+			StyleID: styles.link,
+			Formula: fmt.Sprintf(`HYPERLINK("%s","#%d")`, dashboardResultURL(row.ID), row.ID),
+		}
+		return sw.SetRow(cell, append(row.values(), link))
+	})
+	if err != nil {
+		return err
+	}
+
+	return sw.Flush()
+}
+
+// writeStepsSheet writes a row per step of the filtered results, embedding the screenshots of the
+// first maxExportPictures steps as thumbnails that open the full image and linking the later ones.
+// Pictures are anchored from the column widths and row heights of the sheet, which a streamed
+// sheet does not expose, so this sheet is built in normal mode.
+func (rc *ResultController) writeStepsSheet(c *gin.Context, f *excelize.File, sheet string, styles xlsxStyles) error {
+	query, err := filterResults(rc.DB.Table("result_details"), c)
+	if err != nil {
+		return err
+	}
+	query = query.Select("result_details.result_id, result_details.created_at, COALESCE(sites.name, 'N/A') AS site_name, " +
+		"COALESCE(devices.name, 'N/A') AS device_name, COALESCE(results.browser, '') AS browser, " +
+		"COALESCE(features.name, 'N/A') AS feature_name, COALESCE(result_details.description, '') AS description, " +
+		"COALESCE(result_details.screenshot, '') AS screenshot").
+		Joins("JOIN results ON results.id = result_details.result_id").
+		Joins("LEFT JOIN sites ON sites.id = results.site_id").
+		Joins("LEFT JOIN devices ON devices.id = results.device_id").
+		Joins("LEFT JOIN features ON features.id = results.feature_id").
+		Order("results.created_at DESC, result_details.id ASC")
+
+	if err := setHeader(f, sheet, stepHeaders, []float64{10, 20, 20, 20, 15, 20, 50, 40}, styles.header); err != nil {
+		return err
+	}
+
+	rows, err := query.Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	baseURL := requestBaseURL(c)
+	rowNumber := 1
+	pictures := 0
+	for rows.Next() {
+		var step stepRow
+		if err := rc.DB.ScanRows(rows, &step); err != nil {
+			return err
+		}
+		rowNumber++
+
+		resultCell := fmt.Sprintf("A%d", rowNumber)
+		values := []interface{}{fmt.Sprintf("#%d", step.ResultID), step.CreatedAt.Format("2006-01-02 15:04:05"), step.SiteName, step.DeviceName, step.Browser, step.FeatureName, step.Description}
+		if err := f.SetSheetRow(sheet, resultCell, &values); err != nil {
+			return err
+		}
+		if err := f.SetCellHyperLink(sheet, resultCell, dashboardResultURL(step.ResultID), "External"); err != nil {
+			return err
+		}
+		if err := f.SetCellStyle(sheet, resultCell, resultCell, styles.link); err != nil {
+			return err
+		}
+		if err := f.SetCellStyle(sheet, fmt.Sprintf("G%d", rowNumber), fmt.Sprintf("G%d", rowNumber), styles.wrap); err != nil {
+			return err
+		}
+
+		if step.Screenshot == "" {
+			continue
+		}
+		screenshotCell := fmt.Sprintf("H%d", rowNumber)
+		screenshotURL := baseURL + "/" + strings.TrimPrefix(step.Screenshot, "/")
+
+		// Pictures are kept in memory until the workbook is written, so only the first ones are embedded
+		if path, ok := report.LocalScreenshot(step.Screenshot); ok && pictures < maxExportPictures {
+			// Missing or unreadable screenshots are linked instead
+			if scale, err := thumbnailScale(path); err == nil {
+				if err := f.SetRowHeight(sheet, rowNumber, thumbnailRowHeight); err != nil {
+					return err
+				}
+				if err := f.AddPicture(sheet, screenshotCell, path, &excelize.GraphicOptions{
+					AltText:       step.Description,
+					ScaleX:        scale,
+					ScaleY:        scale,
+					Hyperlink:     screenshotURL,
+					HyperlinkType: "External",
+				}); err != nil {
+					return err
+				}
+				pictures++
+				continue
+			}
+		}
+
+		if err := f.SetCellValue(sheet, screenshotCell, step.Screenshot); err != nil {
+			return err
+		}
+		if err := f.SetCellHyperLink(sheet, screenshotCell, screenshotURL, "External"); err != nil {
+			return err
+		}
+		if err := f.SetCellStyle(sheet, screenshotCell, screenshotCell, styles.link); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
+// thumbnailScale returns the scale that brings a screenshot to the thumbnail height
+func thumbnailScale(path string) (float64, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	config, _, err := image.DecodeConfig(file)
+	if err != nil {
+		return 0, err
+	}
+	if config.Height == 0 {
+		return 0, fmt.Errorf("empty screenshot: %s", path)
+	}
+	return thumbnailHeight / float64(config.Height), nil
+}
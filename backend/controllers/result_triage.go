@@ -0,0 +1,193 @@
+package controllers
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"qa-automation-system/backend/models"
+)
+
+// validTriageStatus reports whether the status is one of models.TriageStatuses
+func validTriageStatus(status string) bool {
+	for _, known := range models.TriageStatuses {
+		if status == known {
+			return true
+		}
+	}
+	return false
+}
+
+// UpdateTriage sets the triage status, assignee and bug URL of a failed result. Marking a failure
+// as a known issue also links the untriaged failures with the same signature to its bug.
+func (rc *ResultController) UpdateTriage(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
+	}
+
+	var payload struct {
+		TriageStatus *string `json:"triage_status"`
+		Assignee     *string `json:"assignee"`
+		BugURL       *string `json:"bug_url"`
+	}
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var result models.Result
+	if err := rc.DB.First(&result, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Result not found"})
+		return
+	}
+	if result.Status != "failed" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Only failed results can be triaged"})
+		return
+	}
+
+	updates := make(map[string]interface{})
+	if payload.TriageStatus != nil {
+		if !validTriageStatus(*payload.TriageStatus) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid triage_status, expected new, investigating, known_issue, fixed or wont_fix"})
+			return
+		}
+		updates["triage_status"] = *payload.TriageStatus
+		result.TriageStatus = *payload.TriageStatus
+	}
+	if payload.Assignee != nil {
+		updates["assignee"] = *payload.Assignee
+		result.Assignee = *payload.Assignee
+	}
+	if payload.BugURL != nil {
+		if *payload.BugURL != "" {
+			parsed, err := url.ParseRequestURI(*payload.BugURL)
+			if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid bug_url, expected an http or https URL"})
+				return
+			}
+		}
+		updates["bug_url"] = *payload.BugURL
+		result.BugURL = *payload.BugURL
+	}
+	if len(updates) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "triage_status, assignee or bug_url is required"})
+		return
+	}
+
+	if result.TriageStatus == models.TriageKnownIssue && result.BugURL == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "A known issue needs a bug_url"})
+		return
+	}
+
+	linked := int64(0)
+	err = rc.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.Result{}).Where("id = ?", result.ID).Updates(updates).Error; err != nil {
+			return err
+		}
+
+		if result.TriageStatus != models.TriageKnownIssue || result.FailureSignature == "" {
+			return nil
+		}
+		link := tx.Model(&models.Result{}).
+			Where("failure_signature = ? AND triage_status = ? AND id <> ?", result.FailureSignature, models.TriageNew, result.ID).
+			Updates(map[string]interface{}{
+				"triage_status": models.TriageKnownIssue,
+				"assignee":      result.Assignee,
+				"bug_url":       result.BugURL,
+			})
+		linked = link.RowsAffected
+		return link.Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":   result,
+		"linked": linked,
+	})
+}
+
+// GetComments retrieves the triage thread of a result, oldest first
+func (rc *ResultController) GetComments(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
+	}
+
+	var comments []models.ResultComment
+	if err := rc.DB.Where("result_id = ?", id).Order("id ASC").Find(&comments).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, comments)
+}
+
+// CreateComment adds a comment to the triage thread of a result
+func (rc *ResultController) CreateComment(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
+	}
+
+	var payload struct {
+		Author string `json:"author" binding:"required"`
+		Body   string `json:"body" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var count int64
+	if err := rc.DB.Model(&models.Result{}).Where("id = ?", id).Count(&count).Error; err != nil || count == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Result not found"})
+		return
+	}
+
+	comment := models.ResultComment{
+		ResultID: uint(id),
+		Author:   payload.Author,
+		Body:     payload.Body,
+	}
+	if err := rc.DB.Create(&comment).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, comment)
+}
+
+// DeleteComment removes a comment from the triage thread of a result
+func (rc *ResultController) DeleteComment(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
+	}
+	commentID, err := strconv.ParseUint(c.Param("comment_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid comment ID"})
+		return
+	}
+
+	deleted := rc.DB.Where("id = ? AND result_id = ?", commentID, id).Delete(&models.ResultComment{})
+	if deleted.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": deleted.Error.Error()})
+		return
+	}
+	if deleted.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Comment not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Comment deleted successfully"})
+}
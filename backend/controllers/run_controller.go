@@ -0,0 +1,191 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"qa-automation-system/backend/models"
+	"qa-automation-system/backend/pkg/report"
+)
+
+// RunController handles run-related operations
+type RunController struct {
+	DB *gorm.DB
+}
+
+// NewRunController creates a new run controller
+func NewRunController(db *gorm.DB) *RunController {
+	return &RunController{DB: db}
+}
+
+// GetByID retrieves a run with the results of its features
+func (c *RunController) GetByID(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
+	}
+
+	var run models.Run
+	if err := c.DB.Preload("Site").Preload("Device").Preload("Results", func(db *gorm.DB) *gorm.DB {
+		return db.Order("id ASC")
+	}).Preload("Results.Feature").First(&run, id).Error; err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Run not found"})
+		return
+	}
+
+	// Failures of quarantined combinations don't fail the run
+	if err := markQuarantined(c.DB, run.Results); err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	run.Status = run.AggregateStatus()
+
+	ctx.JSON(http.StatusOK, run)
+}
+
+// GetJUnit returns the results of a run as a JUnit XML report with a suite per browser, leaving out
+// the failed attempts that were retried
+func (c *RunController) GetJUnit(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
+	}
+
+	var run models.Run
+	if err := c.DB.Preload("Site").Preload("Device").Preload("Results", func(db *gorm.DB) *gorm.DB {
+		return db.Order("id ASC")
+	}).Preload("Results.Site").Preload("Results.Device").Preload("Results.Feature").Preload("Results.Details", func(db *gorm.DB) *gorm.DB {
+		return db.Order("id ASC")
+	}).First(&run, id).Error; err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Run not found"})
+		return
+	}
+
+	if err := markQuarantined(c.DB, run.Results); err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Group the final attempts by browser, keeping the order they were run in
+	var browsers []string
+	byBrowser := make(map[string][]models.Result)
+	for _, result := range run.FinalAttempts() {
+		if _, ok := byBrowser[result.Browser]; !ok {
+			browsers = append(browsers, result.Browser)
+		}
+		byBrowser[result.Browser] = append(byBrowser[result.Browser], result)
+	}
+
+	junit := report.JUnitTestSuites{Name: fmt.Sprintf("Run %d", run.ID)}
+	for _, browser := range browsers {
+		name := fmt.Sprintf("%s on %s (%s)", run.Site.Name, run.Device.Name, browser)
+		junit.Suites = append(junit.Suites, report.NewJUnitTestSuite(name, run.CreatedAt, byBrowser[browser]))
+	}
+
+	ctx.Header("Content-Type", "application/xml")
+	ctx.Header("Content-Disposition", fmt.Sprintf("attachment; filename=run_%d_junit.xml", run.ID))
+	ctx.Status(http.StatusOK)
+	if err := report.WriteJUnit(ctx.Writer, junit); err != nil {
+		fmt.Printf("Error writing JUnit report: %v\n", err)
+	}
+}
+
+// GetReport returns the results of a run as a self-contained HTML report
+func (c *RunController) GetReport(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
+	}
+
+	options, err := htmlOptions(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var run models.Run
+	if err := c.DB.Preload("Site").Preload("Device").Preload("Results", func(db *gorm.DB) *gorm.DB {
+		return db.Order("id ASC")
+	}).Preload("Results.Site").Preload("Results.Device").Preload("Results.Feature").Preload("Results.Details", func(db *gorm.DB) *gorm.DB {
+		return db.Order("id ASC")
+	}).First(&run, id).Error; err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Run not found"})
+		return
+	}
+
+	if err := markQuarantined(c.DB, run.Results); err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	title := fmt.Sprintf("Run %d: %s on %s", run.ID, run.Site.Name, run.Device.Name)
+	htmlReport := report.NewHTMLReport(title, run.FinalAttempts(), options)
+
+	ctx.Header("Content-Type", "text/html; charset=utf-8")
+	ctx.Header("Content-Disposition", fmt.Sprintf("attachment; filename=run_%d_report.html", run.ID))
+	ctx.Status(http.StatusOK)
+	if err := report.WriteHTML(ctx.Writer, htmlReport); err != nil {
+		fmt.Printf("Error writing HTML report: %v\n", err)
+	}
+}
+
+// Rerun runs the final attempts of a run again on their browsers with the same settings and
+// credentials, only the failed ones with only=failed
+func (c *RunController) Rerun(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
+	}
+
+	only := ctx.Query("only")
+	if only != "" && only != "failed" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid only, expected failed"})
+		return
+	}
+
+	var run models.Run
+	if err := c.DB.Preload("Results", func(db *gorm.DB) *gorm.DB {
+		return db.Order("id ASC")
+	}).First(&run, id).Error; err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Run not found"})
+		return
+	}
+
+	var results []models.Result
+	for _, result := range run.FinalAttempts() {
+		if result.Browser == "" || (only == "failed" && result.Status != "failed") {
+			continue
+		}
+		results = append(results, result)
+	}
+	if len(results) == 0 {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Run has no results to rerun"})
+		return
+	}
+
+	email, password, credentialsRef, err := rerunCredentials(ctx, run)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	rerun, err := startRerun(c.DB, run, results, email, password, credentialsRef)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message":     "Rerun started in background",
+		"run_id":      rerun.ID,
+		"rerun_of_id": run.ID,
+		"results":     len(results),
+	})
+}
@@ -22,9 +22,16 @@ func main() {
 		log.Fatalf("Failed to create screenshots directory: %v", err)
 	}
 
-	// Serve static files from screenshots directory
+	// Create artifacts directory if it doesn't exist
+	artifactsDir := "artifacts"
+	if err := os.MkdirAll(artifactsDir, 0755); err != nil {
+		log.Fatalf("Failed to create artifacts directory: %v", err)
+	}
+
+	// Serve static files from screenshots and artifacts directories
 	router := routes.SetupRouter(db)
 	router.Static("/screenshots", "./screenshots")
+	router.Static("/artifacts", "./artifacts")
 
 	// Configure CORS
 	router.Use(cors.New(cors.Config{
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS result_artifacts;
\ No newline at end of file
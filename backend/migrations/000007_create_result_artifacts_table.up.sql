@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS result_artifacts (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    result_id BIGINT UNSIGNED NOT NULL,
+    type VARCHAR(50) NOT NULL,
+    path VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    INDEX idx_result_artifacts_result_id (result_id),
+    FOREIGN KEY (result_id) REFERENCES results(id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
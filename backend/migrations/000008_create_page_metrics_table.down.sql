@@ -0,0 +1 @@
+DROP TABLE IF EXISTS page_metrics;
\ No newline at end of file
@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS page_metrics (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    result_id BIGINT UNSIGNED NOT NULL,
+    page VARCHAR(100) NOT NULL,
+    url VARCHAR(255) NULL,
+    time_to_first_byte FLOAT NULL,
+    dom_content_loaded FLOAT NULL,
+    load_event_end FLOAT NULL,
+    first_paint FLOAT NULL,
+    first_contentful_paint FLOAT NULL,
+    largest_contentful_paint FLOAT NULL,
+    cumulative_layout_shift FLOAT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    INDEX idx_page_metrics_result_id (result_id),
+    FOREIGN KEY (result_id) REFERENCES results(id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS performance_budgets;
\ No newline at end of file
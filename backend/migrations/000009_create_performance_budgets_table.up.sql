@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS performance_budgets (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    site_id BIGINT UNSIGNED NOT NULL,
+    page VARCHAR(100) NOT NULL,
+    metric VARCHAR(50) NOT NULL,
+    threshold DOUBLE NOT NULL,
+    severity ENUM('warning', 'failed') DEFAULT 'warning' NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    INDEX idx_performance_budgets_site_id (site_id),
+    FOREIGN KEY (site_id) REFERENCES sites(id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS result_findings;
\ No newline at end of file
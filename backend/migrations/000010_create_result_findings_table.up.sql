@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS result_findings (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    result_id BIGINT UNSIGNED NOT NULL,
+    type VARCHAR(50) NOT NULL,
+    page VARCHAR(255) NULL,
+    rule VARCHAR(100) NULL,
+    impact VARCHAR(20) NULL,
+    selector TEXT NULL,
+    url VARCHAR(512) NULL,
+    status_code INT NULL,
+    message TEXT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    INDEX idx_result_findings_result_id (result_id),
+    FOREIGN KEY (result_id) REFERENCES results(id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
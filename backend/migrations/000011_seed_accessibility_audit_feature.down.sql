@@ -0,0 +1,2 @@
+-- Remove accessibility audit feature
+DELETE FROM features WHERE name = 'Accessibility Audit';
@@ -0,0 +1,3 @@
+-- Seed accessibility audit feature
+INSERT INTO features (name, created_at, updated_at) VALUES
+('Accessibility Audit', NOW(), NOW());
@@ -0,0 +1,2 @@
+-- Remove link check feature
+DELETE FROM features WHERE name = 'Link Check';
@@ -0,0 +1,3 @@
+-- Seed link check feature
+INSERT INTO features (name, created_at, updated_at) VALUES
+('Link Check', NOW(), NOW());
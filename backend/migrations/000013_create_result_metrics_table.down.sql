@@ -0,0 +1 @@
+DROP TABLE IF EXISTS result_metrics;
\ No newline at end of file
@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS result_metrics (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    result_id BIGINT UNSIGNED NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    value DOUBLE NOT NULL,
+    unit VARCHAR(20) NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    INDEX idx_result_metrics_result_id (result_id),
+    FOREIGN KEY (result_id) REFERENCES results(id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
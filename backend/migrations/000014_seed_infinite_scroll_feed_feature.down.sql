@@ -0,0 +1,2 @@
+-- Remove infinite scroll feed feature
+DELETE FROM features WHERE name = 'Infinite Scroll Feed';
\ No newline at end of file
@@ -0,0 +1,3 @@
+-- Seed infinite scroll feed feature
+INSERT INTO features (name, created_at, updated_at) VALUES
+('Infinite Scroll Feed', NOW(), NOW());
@@ -0,0 +1,2 @@
+-- Remove login flow features
+DELETE FROM features WHERE name IN ('Valid Login', 'Invalid Password', 'Logout', 'Session Persistence');
\ No newline at end of file
@@ -0,0 +1,6 @@
+-- Seed login flow features
+INSERT INTO features (name, created_at, updated_at) VALUES
+('Valid Login', NOW(), NOW()),
+('Invalid Password', NOW(), NOW()),
+('Logout', NOW(), NOW()),
+('Session Persistence', NOW(), NOW());
@@ -0,0 +1 @@
+ALTER TABLE features DROP COLUMN fresh_login;
\ No newline at end of file
@@ -0,0 +1,4 @@
+ALTER TABLE features ADD COLUMN fresh_login TINYINT(1) NOT NULL DEFAULT 0 AFTER name;
+
+-- Features testing the login itself always go through the login form
+UPDATE features SET fresh_login = 1 WHERE name IN ('Valid Login', 'Invalid Password', 'Logout', 'Session Persistence');
@@ -0,0 +1,2 @@
+ALTER TABLE results DROP FOREIGN KEY fk_results_run_id, DROP INDEX idx_results_run_id, DROP COLUMN run_id;
+DROP TABLE IF EXISTS runs;
\ No newline at end of file
@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS runs (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    site_id BIGINT UNSIGNED NOT NULL,
+    device_id BIGINT UNSIGNED NOT NULL,
+    single_session TINYINT(1) NOT NULL DEFAULT 0,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    FOREIGN KEY (site_id) REFERENCES sites(id) ON DELETE CASCADE,
+    FOREIGN KEY (device_id) REFERENCES devices(id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+ALTER TABLE results
+    ADD COLUMN run_id BIGINT UNSIGNED NULL AFTER id,
+    ADD INDEX idx_results_run_id (run_id),
+    ADD CONSTRAINT fk_results_run_id FOREIGN KEY (run_id) REFERENCES runs(id) ON DELETE SET NULL;
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS result_hooks;
\ No newline at end of file
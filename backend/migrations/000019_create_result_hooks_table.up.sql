@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS result_hooks (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    result_id BIGINT UNSIGNED NOT NULL,
+    phase VARCHAR(10) NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    kind VARCHAR(20) NOT NULL,
+    status VARCHAR(20) NOT NULL,
+    duration FLOAT NULL,
+    message TEXT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    INDEX idx_result_hooks_result_id (result_id),
+    FOREIGN KEY (result_id) REFERENCES results(id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
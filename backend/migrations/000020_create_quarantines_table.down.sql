@@ -0,0 +1 @@
+DROP TABLE IF EXISTS quarantines;
\ No newline at end of file
@@ -0,0 +1,3 @@
+ALTER TABLE results DROP FOREIGN KEY fk_results_retry_of_id, DROP INDEX idx_results_retry_of_id, DROP COLUMN passed_on_retry, DROP COLUMN attempt, DROP COLUMN retry_of_id;
+ALTER TABLE runs DROP COLUMN retry_backoff, DROP COLUMN retry_on, DROP COLUMN retry_max_attempts;
+ALTER TABLE features DROP COLUMN retry_backoff, DROP COLUMN retry_on, DROP COLUMN retry_max_attempts;
\ No newline at end of file
@@ -0,0 +1,16 @@
+ALTER TABLE features
+    ADD COLUMN retry_max_attempts INT NOT NULL DEFAULT 0,
+    ADD COLUMN retry_on VARCHAR(255) NULL,
+    ADD COLUMN retry_backoff INT NOT NULL DEFAULT 0;
+
+ALTER TABLE runs
+    ADD COLUMN retry_max_attempts INT NOT NULL DEFAULT 0 AFTER single_session,
+    ADD COLUMN retry_on VARCHAR(255) NULL AFTER retry_max_attempts,
+    ADD COLUMN retry_backoff INT NOT NULL DEFAULT 0 AFTER retry_on;
+
+ALTER TABLE results
+    ADD COLUMN retry_of_id BIGINT UNSIGNED NULL AFTER run_id,
+    ADD COLUMN attempt INT NOT NULL DEFAULT 1 AFTER retry_of_id,
+    ADD COLUMN passed_on_retry TINYINT(1) NOT NULL DEFAULT 0 AFTER attempt,
+    ADD INDEX idx_results_retry_of_id (retry_of_id),
+    ADD CONSTRAINT fk_results_retry_of_id FOREIGN KEY (retry_of_id) REFERENCES results(id) ON DELETE SET NULL;
@@ -0,0 +1,2 @@
+ALTER TABLE results DROP FOREIGN KEY fk_results_rerun_of_id, DROP INDEX idx_results_rerun_of_id, DROP COLUMN rerun_of_id;
+ALTER TABLE runs DROP FOREIGN KEY fk_runs_rerun_of_id, DROP INDEX idx_runs_rerun_of_id, DROP COLUMN rerun_of_id, DROP COLUMN credentials_ref, DROP COLUMN email, DROP COLUMN capture_har;
\ No newline at end of file
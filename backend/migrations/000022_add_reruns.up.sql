@@ -0,0 +1,12 @@
+ALTER TABLE runs
+    ADD COLUMN capture_har TINYINT(1) NOT NULL DEFAULT 0 AFTER retry_backoff,
+    ADD COLUMN email VARCHAR(255) NULL AFTER capture_har,
+    ADD COLUMN credentials_ref VARCHAR(20) NOT NULL DEFAULT 'env' AFTER email,
+    ADD COLUMN rerun_of_id BIGINT UNSIGNED NULL AFTER credentials_ref,
+    ADD INDEX idx_runs_rerun_of_id (rerun_of_id),
+    ADD CONSTRAINT fk_runs_rerun_of_id FOREIGN KEY (rerun_of_id) REFERENCES runs(id) ON DELETE SET NULL;
+
+ALTER TABLE results
+    ADD COLUMN rerun_of_id BIGINT UNSIGNED NULL AFTER attempt,
+    ADD INDEX idx_results_rerun_of_id (rerun_of_id),
+    ADD CONSTRAINT fk_results_rerun_of_id FOREIGN KEY (rerun_of_id) REFERENCES results(id) ON DELETE SET NULL;
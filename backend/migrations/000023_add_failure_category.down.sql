@@ -0,0 +1 @@
+ALTER TABLE results DROP INDEX idx_results_failure_category, DROP COLUMN failure_category;
\ No newline at end of file
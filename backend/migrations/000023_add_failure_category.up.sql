@@ -0,0 +1,15 @@
+ALTER TABLE results
+    ADD COLUMN failure_category VARCHAR(30) NULL AFTER error_log,
+    ADD INDEX idx_results_failure_category (failure_category);
+
+-- Classify the failures recorded so far, in the order of the runner's failure patterns
+UPDATE results SET failure_category = CASE
+    WHEN error_log REGEXP 'not been implemented|not implemented' THEN 'not-implemented'
+    WHEN error_log REGEXP 'webdriver|browserstack|driver not initialized|session not created|invalid session id|no such (window|session)|failed to initialize|connection (refused|reset)|broken pipe|unexpected EOF|\\b50[234]\\b|bad gateway|service unavailable' THEN 'infrastructure'
+    WHEN error_log REGEXP 'still on login page|credential|invalid (email|password)|wrong password|unauthori[sz]ed|\\b401\\b|account (locked|disabled)' THEN 'credentials'
+    WHEN error_log REGEXP 'timed? ?out|deadline exceeded|within [0-9]' THEN 'timeout'
+    WHEN error_log REGEXP 'no such element|unable to locate|failed to find|not found|no visible|stale element' THEN 'selector-not-found'
+    WHEN error_log REGEXP 'navigat|not on .* page|failed to load|redirect|failed to (get current url|reload)|session lost|ERR_[A-Z_]+' THEN 'navigation'
+    ELSE 'assertion'
+END
+WHERE status = 'failed';
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS result_comments;
+ALTER TABLE results DROP INDEX idx_results_assignee, DROP INDEX idx_results_triage_status, DROP INDEX idx_results_failure_signature, DROP COLUMN bug_url, DROP COLUMN assignee, DROP COLUMN triage_status, DROP COLUMN failure_signature;
\ No newline at end of file
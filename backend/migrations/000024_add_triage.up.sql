@@ -0,0 +1,22 @@
+ALTER TABLE results
+    ADD COLUMN failure_signature CHAR(40) NULL AFTER failure_category,
+    ADD COLUMN triage_status VARCHAR(20) NULL AFTER failure_signature,
+    ADD COLUMN assignee VARCHAR(255) NULL AFTER triage_status,
+    ADD COLUMN bug_url VARCHAR(512) NULL AFTER assignee,
+    ADD INDEX idx_results_failure_signature (failure_signature),
+    ADD INDEX idx_results_triage_status (triage_status),
+    ADD INDEX idx_results_assignee (assignee);
+
+-- Failures recorded so far wait for triage
+UPDATE results SET triage_status = 'new' WHERE status = 'failed';
+
+CREATE TABLE IF NOT EXISTS result_comments (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    result_id BIGINT UNSIGNED NOT NULL,
+    author VARCHAR(255) NOT NULL,
+    body TEXT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    INDEX idx_result_comments_result_id (result_id),
+    FOREIGN KEY (result_id) REFERENCES results(id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
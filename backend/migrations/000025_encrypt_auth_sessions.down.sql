@@ -0,0 +1,3 @@
+ALTER TABLE auth_sessions
+    MODIFY cookies TEXT NULL,
+    MODIFY local_storage TEXT NULL;
\ No newline at end of file
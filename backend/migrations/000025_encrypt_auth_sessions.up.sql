@@ -0,0 +1,6 @@
+-- Saved logins are encrypted from now on, drop the ones stored in plaintext
+DELETE FROM auth_sessions;
+
+ALTER TABLE auth_sessions
+    MODIFY cookies MEDIUMTEXT NULL,
+    MODIFY local_storage MEDIUMTEXT NULL;
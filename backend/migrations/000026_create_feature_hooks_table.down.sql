@@ -0,0 +1 @@
+DROP TABLE IF EXISTS feature_hooks;
\ No newline at end of file
@@ -0,0 +1,28 @@
+CREATE TABLE IF NOT EXISTS feature_hooks (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    feature_id BIGINT UNSIGNED NOT NULL,
+    phase VARCHAR(10) NOT NULL,
+    position INT NOT NULL DEFAULT 0,
+    name VARCHAR(255) NOT NULL,
+    kind VARCHAR(20) NOT NULL,
+    action VARCHAR(100) NULL,
+    selector VARCHAR(255) NULL,
+    method VARCHAR(10) NULL,
+    url VARCHAR(512) NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    INDEX idx_feature_hooks_feature_id (feature_id),
+    FOREIGN KEY (feature_id) REFERENCES features(id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- Subscribing changes the account, start and end every run as a free account
+INSERT INTO feature_hooks (feature_id, phase, position, name, kind, action, method, url, created_at, updated_at)
+SELECT id, 'before', 1, 'Reset premium subscription', 'http', NULL, 'POST', '${PREMIUM_RESET_URL}', NOW(), NOW() FROM features WHERE name = 'Premium Subscription'
+UNION ALL
+SELECT id, 'after', 1, 'Close payment dialogs', 'ui', 'press_escape', NULL, NULL, NOW(), NOW() FROM features WHERE name = 'Premium Subscription'
+UNION ALL
+SELECT id, 'after', 2, 'Reset premium subscription', 'http', NULL, 'POST', '${PREMIUM_RESET_URL}', NOW(), NOW() FROM features WHERE name = 'Premium Subscription';
+
+-- Logging out ends the saved session on the server
+INSERT INTO feature_hooks (feature_id, phase, position, name, kind, action, created_at, updated_at)
+SELECT id, 'after', 1, 'Remove saved login session', 'func', 'remove_saved_session', NOW(), NOW() FROM features WHERE name = 'Logout';
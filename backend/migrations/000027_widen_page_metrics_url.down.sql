@@ -0,0 +1,4 @@
+UPDATE page_metrics SET url = LEFT(url, 255) WHERE CHAR_LENGTH(url) > 255;
+
+ALTER TABLE page_metrics
+    MODIFY url VARCHAR(255) NULL;
\ No newline at end of file
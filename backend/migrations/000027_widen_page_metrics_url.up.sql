@@ -0,0 +1,2 @@
+ALTER TABLE page_metrics
+    MODIFY url VARCHAR(2048) NULL;
@@ -0,0 +1,23 @@
+package models
+
+// Failure categories of a failed result, derived from its error log
+const (
+	FailureSelectorNotFound = "selector-not-found"
+	FailureNavigation       = "navigation"
+	FailureTimeout          = "timeout"
+	FailureAssertion        = "assertion"
+	FailureInfrastructure   = "infrastructure"
+	FailureCredentials      = "credentials"
+	FailureNotImplemented   = "not-implemented"
+)
+
+// FailureCategories lists every failure category
+var FailureCategories = []string{
+	FailureSelectorNotFound,
+	FailureNavigation,
+	FailureTimeout,
+	FailureAssertion,
+	FailureInfrastructure,
+	FailureCredentials,
+	FailureNotImplemented,
+}
@@ -0,0 +1,29 @@
+package models
+
+import (
+	"time"
+)
+
+// Finding types stored in ResultFinding.Type
+const (
+	FindingTypeAccessibility = "accessibility"
+	FindingTypeBrokenLink    = "broken_link"
+	FindingTypeAssertion     = "assertion"
+)
+
+// ResultFinding represents an issue found on a page during a test run,
+// such as an accessibility violation or a broken link
+type ResultFinding struct {
+	ID         uint      `json:"id" gorm:"primaryKey"`
+	ResultID   uint      `json:"result_id" gorm:"not null;index"`
+	Type       string    `json:"type" gorm:"type:varchar(50);not null"`
+	Page       string    `json:"page" gorm:"type:varchar(255);null"`
+	Rule       string    `json:"rule" gorm:"type:varchar(100);null"`
+	Impact     string    `json:"impact" gorm:"type:varchar(20);null"`
+	Selector   string    `json:"selector" gorm:"type:text;null"`
+	URL        string    `json:"url" gorm:"type:varchar(512);null"`
+	StatusCode int       `json:"status_code" gorm:"null"`
+	Message    string    `json:"message" gorm:"type:text;null"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
@@ -0,0 +1,60 @@
+package models
+
+import (
+	"time"
+)
+
+// Hook phases stored in ResultHook.Phase
+const (
+	HookPhaseBefore = "before"
+	HookPhaseAfter  = "after"
+)
+
+// Hook kinds stored in ResultHook.Kind
+const (
+	HookKindUI   = "ui"
+	HookKindHTTP = "http"
+	HookKindFunc = "func"
+)
+
+// Hook statuses stored in ResultHook.Status
+const (
+	HookStatusPassed = "passed"
+	HookStatusFailed = "failed"
+)
+
+// FeatureHook represents a setup or teardown step run around every test of a feature, in
+// the order of Position within its phase
+type FeatureHook struct {
+	ID        uint   `json:"id" gorm:"primaryKey"`
+	FeatureID uint   `json:"feature_id" gorm:"not null;index"`
+	Phase     string `json:"phase" gorm:"type:varchar(10);not null"`
+	Position  int    `json:"position" gorm:"not null;default:0"`
+	Name      string `json:"name" gorm:"type:varchar(255);not null"`
+	Kind      string `json:"kind" gorm:"type:varchar(20);not null"`
+	// Action names the browser step of a ui hook or the Go function of a func hook
+	Action string `json:"action" gorm:"type:varchar(100);null"`
+	// Selector is the element the browser step of a ui hook acts on, when it takes one
+	Selector string `json:"selector" gorm:"type:varchar(255);null"`
+	// Method and URL are the request of an http hook, the URL can reference environment
+	// variables as ${NAME}
+	Method    string    `json:"method" gorm:"type:varchar(10);null"`
+	URL       string    `json:"url" gorm:"type:varchar(512);null"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// ResultHook represents the outcome of a setup or teardown hook run around a feature test,
+// kept apart from the result status so cleanup failures don't hide the test outcome
+type ResultHook struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	ResultID  uint      `json:"result_id" gorm:"not null;index"`
+	Phase     string    `json:"phase" gorm:"type:varchar(10);not null"`
+	Name      string    `json:"name" gorm:"type:varchar(255);not null"`
+	Kind      string    `json:"kind" gorm:"type:varchar(20);not null"`
+	Status    string    `json:"status" gorm:"type:varchar(20);not null"`
+	Duration  float64   `json:"duration" gorm:"type:float;null"`
+	Message   string    `json:"message" gorm:"type:text;null"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
@@ -0,0 +1,102 @@
+package models
+
+import (
+	"time"
+)
+
+// MaxPageMetricURLLength is the length of the longest URL stored with page metrics
+const MaxPageMetricURLLength = 2048
+
+// PageMetric represents the page performance timings collected after a navigation step.
+// Timings are in milliseconds since the start of the navigation; browsers without
+// paint, LCP or layout shift support leave those fields empty.
+type PageMetric struct {
+	ID                     uint      `json:"id" gorm:"primaryKey"`
+	ResultID               uint      `json:"result_id" gorm:"not null;index"`
+	Page                   string    `json:"page" gorm:"type:varchar(100);not null"`
+	URL                    string    `json:"url" gorm:"type:varchar(2048);null"`
+	TimeToFirstByte        float64   `json:"ttfb" gorm:"type:float;null"`
+	DOMContentLoaded       float64   `json:"dom_content_loaded" gorm:"type:float;null"`
+	LoadEventEnd           float64   `json:"load_event_end" gorm:"type:float;null"`
+	FirstPaint             *float64  `json:"first_paint" gorm:"type:float;null"`
+	FirstContentfulPaint   *float64  `json:"first_contentful_paint" gorm:"type:float;null"`
+	LargestContentfulPaint *float64  `json:"largest_contentful_paint" gorm:"type:float;null"`
+	CumulativeLayoutShift  *float64  `json:"cumulative_layout_shift" gorm:"type:float;null"`
+	CreatedAt              time.Time `json:"created_at"`
+	UpdatedAt              time.Time `json:"updated_at"`
+}
+
+// ResultMetric represents a named measurement taken during a test, such as the
+// round-trip latency of a chat message
+type ResultMetric struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	ResultID  uint      `json:"result_id" gorm:"not null;index"`
+	Name      string    `json:"name" gorm:"type:varchar(100);not null"`
+	Value     float64   `json:"value" gorm:"not null"`
+	Unit      string    `json:"unit" gorm:"type:varchar(20);null"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Page metric names used by performance budgets
+const (
+	MetricTimeToFirstByte        = "ttfb"
+	MetricDOMContentLoaded       = "dom_content_loaded"
+	MetricLoadEventEnd           = "load_event_end"
+	MetricFirstPaint             = "first_paint"
+	MetricFirstContentfulPaint   = "first_contentful_paint"
+	MetricLargestContentfulPaint = "largest_contentful_paint"
+	MetricCumulativeLayoutShift  = "cumulative_layout_shift"
+)
+
+// PageMetricNames lists every metric that can be budgeted, in display order
+var PageMetricNames = []string{
+	MetricTimeToFirstByte,
+	MetricDOMContentLoaded,
+	MetricLoadEventEnd,
+	MetricFirstPaint,
+	MetricFirstContentfulPaint,
+	MetricLargestContentfulPaint,
+	MetricCumulativeLayoutShift,
+}
+
+// Value returns the named metric, or false when it was not collected
+func (m PageMetric) Value(name string) (float64, bool) {
+	switch name {
+	case MetricTimeToFirstByte:
+		return m.TimeToFirstByte, m.TimeToFirstByte > 0
+	case MetricDOMContentLoaded:
+		return m.DOMContentLoaded, m.DOMContentLoaded > 0
+	case MetricLoadEventEnd:
+		return m.LoadEventEnd, m.LoadEventEnd > 0
+	case MetricFirstPaint:
+		return optionalValue(m.FirstPaint)
+	case MetricFirstContentfulPaint:
+		return optionalValue(m.FirstContentfulPaint)
+	case MetricLargestContentfulPaint:
+		return optionalValue(m.LargestContentfulPaint)
+	case MetricCumulativeLayoutShift:
+		return optionalValue(m.CumulativeLayoutShift)
+	}
+	return 0, false
+}
+
+// optionalValue dereferences a metric that browsers may not report
+func optionalValue(value *float64) (float64, bool) {
+	if value == nil {
+		return 0, false
+	}
+	return *value, true
+}
+
+// PerformanceBudget represents the maximum allowed value of a page metric for a site.
+// Timings are in milliseconds; the layout shift score is unitless.
+type PerformanceBudget struct {
+	Base
+	SiteID    uint    `json:"site_id" gorm:"not null;index"`
+	Page      string  `json:"page" gorm:"type:varchar(100);not null"`
+	Metric    string  `json:"metric" gorm:"type:varchar(50);not null"`
+	Threshold float64 `json:"threshold" gorm:"not null"`
+	Severity  string  `json:"severity" gorm:"type:enum('warning','failed');not null;default:'warning'"`
+	Site      Site    `json:"site" gorm:"foreignKey:SiteID"`
+}
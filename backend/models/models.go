@@ -27,4 +27,10 @@ type Device struct {
 type Feature struct {
 	Base
 	Name string `json:"name" gorm:"unique;not null"`
+	// FreshLogin makes the feature log in through the login form instead of reusing a cached session
+	FreshLogin bool `json:"fresh_login" gorm:"not null;default:false"`
+	// Retry is the default retry policy of the feature's failures
+	Retry RetryPolicy `json:"retry" gorm:"embedded;embeddedPrefix:retry_"`
+	// Hooks are the setup and teardown steps run around the feature's tests
+	Hooks []FeatureHook `json:"hooks,omitempty" gorm:"foreignKey:FeatureID"`
 } 
\ No newline at end of file
@@ -0,0 +1,27 @@
+package models
+
+import (
+	"time"
+)
+
+// Quarantine marks a site, feature, browser and device combination as known to be flaky.
+// Failures of a quarantined combination are still recorded but don't fail the status of a run.
+type Quarantine struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	SiteID    uint      `json:"site_id" gorm:"not null;uniqueIndex:idx_quarantines_combination"`
+	FeatureID uint      `json:"feature_id" gorm:"not null;uniqueIndex:idx_quarantines_combination"`
+	Browser   string    `json:"browser" gorm:"type:varchar(50);not null;uniqueIndex:idx_quarantines_combination"`
+	DeviceID  uint      `json:"device_id" gorm:"not null;uniqueIndex:idx_quarantines_combination"`
+	Reason    string    `json:"reason" gorm:"type:text;null"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	Site      Site      `json:"site" gorm:"foreignKey:SiteID"`
+	Feature   Feature   `json:"feature" gorm:"foreignKey:FeatureID"`
+	Device    Device    `json:"device" gorm:"foreignKey:DeviceID"`
+}
+
+// Matches reports whether the result belongs to the quarantined combination
+func (q Quarantine) Matches(result Result) bool {
+	return q.SiteID == result.SiteID && q.FeatureID == result.FeatureID &&
+		q.Browser == result.Browser && q.DeviceID == result.DeviceID
+}
@@ -7,6 +7,13 @@ import (
 // Result represents a test result
 type Result struct {
 	ID        uint      `json:"id" gorm:"primaryKey"`
+	RunID     *uint     `json:"run_id" gorm:"index;null"`
+	// RetryOfID links a retry to the failed attempt it repeats
+	RetryOfID *uint     `json:"retry_of_id" gorm:"index;null"`
+	Attempt   int       `json:"attempt" gorm:"not null;default:1"`
+	// RerunOfID links a rerun to the result it repeats
+	RerunOfID *uint     `json:"rerun_of_id" gorm:"index;null"`
+	PassedOnRetry bool  `json:"passed_on_retry" gorm:"not null;default:false"`
 	SiteID    uint      `json:"site_id" gorm:"not null"`
 	DeviceID  uint      `json:"device_id" gorm:"not null"`
 	FeatureID uint      `json:"feature_id" gorm:"not null"`
@@ -14,7 +21,14 @@ type Result struct {
 	Browser   string    `json:"browser" gorm:"type:varchar(255);null"`
 	Location  string    `json:"location" gorm:"type:varchar(255);null"`
 	Screenshot string    `json:"screenshot" gorm:"type:varchar(255);null"`
-	ErrorLog  string    `json:"error_log" gorm:"type:varchar(255);null"`
+	ErrorLog  string    `json:"error_log" gorm:"type:text;null"`
+	// FailureCategory is the cause of a failed result derived from its error log, see FailureCategories
+	FailureCategory string `json:"failure_category" gorm:"type:varchar(30);null;index"`
+	// FailureSignature identifies failures with the same cause, see testrunner.FailureSignature
+	FailureSignature string `json:"failure_signature" gorm:"type:char(40);null;index"`
+	TriageStatus string    `json:"triage_status" gorm:"type:varchar(20);null;index"`
+	Assignee  string       `json:"assignee" gorm:"type:varchar(255);null;index"`
+	BugURL    string       `json:"bug_url" gorm:"type:varchar(512);null"`
 	Duration  float64   `json:"duration" gorm:"type:float;null"`
 	VideoPath string    `json:"video_path" gorm:"type:varchar(255);null"`
 	CreatedAt time.Time `json:"created_at"`
@@ -23,6 +37,13 @@ type Result struct {
 	Device    Device    `json:"device" gorm:"foreignKey:DeviceID"`
 	Feature   Feature   `json:"feature" gorm:"foreignKey:FeatureID"`
 	Details   []ResultDetail `json:"details" gorm:"foreignKey:ResultID"`
+	Artifacts []ResultArtifact `json:"artifacts" gorm:"foreignKey:ResultID"`
+	PageMetrics []PageMetric `json:"page_metrics" gorm:"foreignKey:ResultID"`
+	Metrics   []ResultMetric `json:"metrics" gorm:"foreignKey:ResultID"`
+	Findings  []ResultFinding `json:"findings" gorm:"foreignKey:ResultID"`
+	Hooks     []ResultHook `json:"hooks" gorm:"foreignKey:ResultID"`
+	Comments  []ResultComment `json:"comments" gorm:"foreignKey:ResultID"`
+	Quarantined bool     `json:"quarantined" gorm:"-"`
 }
 
 // ResultDetail represents detailed information about a test result
@@ -34,4 +55,19 @@ type ResultDetail struct {
 	CreatedAt   time.Time `json:"created_at"`
 	UpdatedAt   time.Time `json:"updated_at"`
 	Result      Result    `json:"result" gorm:"foreignKey:ResultID"`
-} 
\ No newline at end of file
+} 
+
+// Artifact types stored in ResultArtifact.Type
+const (
+	ArtifactTypeHAR = "har"
+)
+
+// ResultArtifact represents a file captured during a test run, such as a HAR network log
+type ResultArtifact struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	ResultID  uint      `json:"result_id" gorm:"not null;index"`
+	Type      string    `json:"type" gorm:"type:varchar(50);not null"`
+	Path      string    `json:"path" gorm:"type:varchar(255);not null"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
\ No newline at end of file
@@ -0,0 +1,77 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// RetryAny in RetryPolicy.On retries every failure category
+const RetryAny = "any"
+
+// MaxRetryAttempts caps the attempts of a retry policy
+const MaxRetryAttempts = 5
+
+// MaxRetryBackoff caps the backoff of a retry policy, in seconds
+const MaxRetryBackoff = 60
+
+// MaxRetryDelay caps the wait before a retry once the backoff has been doubled
+const MaxRetryDelay = 5 * time.Minute
+
+// DefaultRetryOn are the failure categories retried when a policy names none, the ones
+// caused by the test environment rather than the site
+var DefaultRetryOn = []string{FailureInfrastructure, FailureTimeout}
+
+// RetryPolicy controls how a failed test is retried. A feature holds its default policy,
+// which a run can override.
+type RetryPolicy struct {
+	// MaxAttempts is the number of times the test runs at most, 0 or 1 never retries
+	MaxAttempts int `json:"max_attempts" gorm:"not null;default:0"`
+	// On is a comma-separated list of the failure categories to retry, or any
+	On string `json:"on" gorm:"type:varchar(255);null"`
+	// Backoff is the wait in seconds before the first retry, doubled for every later one,
+	// at most MaxRetryBackoff
+	Backoff int `json:"backoff" gorm:"not null;default:0"`
+}
+
+// Categories returns the failure categories the policy retries
+func (p RetryPolicy) Categories() []string {
+	if strings.TrimSpace(p.On) == "" {
+		return DefaultRetryOn
+	}
+
+	var categories []string
+	for _, category := range strings.Split(p.On, ",") {
+		if category = strings.TrimSpace(category); category != "" {
+			categories = append(categories, category)
+		}
+	}
+	return categories
+}
+
+// ShouldRetry reports whether a failure of the category on the given attempt is retried
+func (p RetryPolicy) ShouldRetry(attempt int, category string) bool {
+	if attempt >= p.MaxAttempts {
+		return false
+	}
+	for _, retried := range p.Categories() {
+		if retried == RetryAny || retried == category {
+			return true
+		}
+	}
+	return false
+}
+
+// Delay returns the wait before the attempt after the given one, at most MaxRetryDelay
+func (p RetryPolicy) Delay(attempt int) time.Duration {
+	if p.Backoff <= 0 {
+		return 0
+	}
+	delay := time.Duration(p.Backoff) * time.Second
+	for i := 1; i < attempt && delay < MaxRetryDelay; i++ {
+		delay *= 2
+	}
+	if delay > MaxRetryDelay {
+		return MaxRetryDelay
+	}
+	return delay
+}
@@ -0,0 +1,79 @@
+package models
+
+import (
+	"time"
+)
+
+// Credential references stored in Run.CredentialsRef. Passwords are never stored, a run
+// records whether it logged in with the account of the environment or one given with the run.
+const (
+	CredentialsEnv    = "env"
+	CredentialsCustom = "custom"
+)
+
+// Run represents one submission of tests on a site and device, grouping the results
+// of its features on every browser
+type Run struct {
+	ID            uint      `json:"id" gorm:"primaryKey"`
+	SiteID        uint      `json:"site_id" gorm:"not null"`
+	DeviceID      uint      `json:"device_id" gorm:"not null"`
+	SingleSession bool      `json:"single_session" gorm:"not null;default:false"`
+	Retry         RetryPolicy `json:"retry" gorm:"embedded;embeddedPrefix:retry_"`
+	CaptureHAR    bool      `json:"capture_har" gorm:"not null;default:false"`
+	Email         string    `json:"email" gorm:"type:varchar(255);null"`
+	CredentialsRef string   `json:"credentials_ref" gorm:"type:varchar(20);not null;default:'env'"`
+	// RerunOfID links a rerun to the run it repeats
+	RerunOfID     *uint     `json:"rerun_of_id" gorm:"index;null"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+	Site          Site      `json:"site" gorm:"foreignKey:SiteID"`
+	Device        Device    `json:"device" gorm:"foreignKey:DeviceID"`
+	Results       []Result  `json:"results" gorm:"foreignKey:RunID"`
+	Status        string    `json:"status" gorm:"-"`
+}
+
+// RetryPolicyFor returns the retry policy of a feature in the run, the run's when it sets one
+func (r Run) RetryPolicyFor(feature Feature) RetryPolicy {
+	if r.Retry.MaxAttempts > 0 {
+		return r.Retry
+	}
+	return feature.Retry
+}
+
+// FinalAttempts returns the results of the run that were not retried
+func (r Run) FinalAttempts() []Result {
+	retried := make(map[uint]bool)
+	for _, result := range r.Results {
+		if result.RetryOfID != nil {
+			retried[*result.RetryOfID] = true
+		}
+	}
+
+	var results []Result
+	for _, result := range r.Results {
+		if !retried[result.ID] {
+			results = append(results, result)
+		}
+	}
+	return results
+}
+
+// AggregateStatus returns the status of the run from the final attempts of its results: processing
+// while any is, failed when a result not quarantined failed, warning when any warned or a quarantined
+// result failed, passed otherwise
+func (r Run) AggregateStatus() string {
+	status := "passed"
+	for _, result := range r.FinalAttempts() {
+		switch {
+		case result.Status == "processing":
+			return "processing"
+		case result.Status == "failed" && !result.Quarantined:
+			status = "failed"
+		case result.Status == "failed" || result.Status == "warning":
+			if status == "passed" {
+				status = "warning"
+			}
+		}
+	}
+	return status
+}
@@ -0,0 +1,19 @@
+package models
+
+import (
+	"time"
+)
+
+// AuthSession represents the browser state saved after logging in to a site,
+// reused by later test runs of the same account to skip the login form. The cookies and
+// local storage are encrypted with AUTH_SESSION_KEY.
+type AuthSession struct {
+	ID           uint      `json:"id" gorm:"primaryKey"`
+	SiteID       uint      `json:"site_id" gorm:"not null;uniqueIndex:idx_auth_sessions_site_email"`
+	Email        string    `json:"email" gorm:"type:varchar(255);not null;uniqueIndex:idx_auth_sessions_site_email"`
+	Cookies      string    `json:"-" gorm:"type:mediumtext"`
+	LocalStorage string    `json:"-" gorm:"type:mediumtext"`
+	ExpiresAt    time.Time `json:"expires_at" gorm:"not null;index"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
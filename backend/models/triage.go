@@ -0,0 +1,33 @@
+package models
+
+import (
+	"time"
+)
+
+// Triage statuses stored in Result.TriageStatus
+const (
+	TriageNew           = "new"
+	TriageInvestigating = "investigating"
+	TriageKnownIssue    = "known_issue"
+	TriageFixed         = "fixed"
+	TriageWontFix       = "wont_fix"
+)
+
+// TriageStatuses lists every triage status
+var TriageStatuses = []string{
+	TriageNew,
+	TriageInvestigating,
+	TriageKnownIssue,
+	TriageFixed,
+	TriageWontFix,
+}
+
+// ResultComment represents a comment in the triage thread of a failed result
+type ResultComment struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	ResultID  uint      `json:"result_id" gorm:"not null;index"`
+	Author    string    `json:"author" gorm:"type:varchar(255);not null"`
+	Body      string    `json:"body" gorm:"type:text;not null"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
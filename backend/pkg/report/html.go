@@ -0,0 +1,222 @@
+package report
+
+import (
+	_ "embed"
+	"encoding/base64"
+	"fmt"
+	"html/template"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"qa-automation-system/backend/models"
+)
+
+//go:embed templates/report.html
+var htmlTemplateSource string
+
+// htmlTemplate renders a report as a single HTML document
+var htmlTemplate = template.Must(template.New("report").Parse(htmlTemplateSource))
+
+// screenshotsDir is where the runner saves screenshots, the only files a report inlines
+const screenshotsDir = "screenshots"
+
+// HTMLOptions controls how screenshots are referenced by a report
+type HTMLOptions struct {
+	// EmbedScreenshots inlines screenshots as base64 data so the report can be archived offline
+	EmbedScreenshots bool
+	// BaseURL is prepended to the screenshots that are linked instead of embedded
+	BaseURL string
+}
+
+// HTMLSummary are the outcome counts of a report, failures of quarantined combinations are
+// counted as Quarantined instead of Failed
+type HTMLSummary struct {
+	Total       int
+	Passed      int
+	Failed      int
+	Quarantined int
+	Warning     int
+	Processing  int
+	Duration    float64
+}
+
+// HTMLGridCell is the status of a feature in one browser
+type HTMLGridCell struct {
+	Status   string
+	ResultID uint
+
+	createdAt time.Time
+}
+
+// HTMLGridRow is a feature, with a cell per browser of the report
+type HTMLGridRow struct {
+	Label string
+	Cells []HTMLGridCell
+}
+
+// HTMLStep is an entry of the step timeline of a result
+type HTMLStep struct {
+	Time        time.Time
+	Description string
+	Screenshot  template.URL
+}
+
+// HTMLResult is a result with its error log and step timeline
+type HTMLResult struct {
+	ID        uint
+	Site      string
+	Device    string
+	Feature   string
+	Browser   string
+	Status    string
+	Duration  float64
+	CreatedAt time.Time
+	ErrorLog  string
+	// FailureCategory is the cause of a failed result
+	FailureCategory string
+	Quarantined     bool
+	PassedOnRetry   bool
+	Steps           []HTMLStep
+}
+
+// HTMLReport is the data rendered by WriteHTML
+type HTMLReport struct {
+	Title       string
+	GeneratedAt time.Time
+	Summary     HTMLSummary
+	Browsers    []string
+	Grid        []HTMLGridRow
+	Results     []HTMLResult
+}
+
+// NewHTMLReport builds a report of the results, with their site, device, feature and details loaded.
+// The grid has a row per feature, site and device, showing the latest result of each browser.
+func NewHTMLReport(title string, results []models.Result, options HTMLOptions) HTMLReport {
+	htmlReport := HTMLReport{Title: title, GeneratedAt: time.Now()}
+
+	browserIndex := make(map[string]int)
+	rowIndex := make(map[string]int)
+	var cells []map[int]HTMLGridCell
+
+	for _, result := range results {
+		htmlReport.Summary.Total++
+		htmlReport.Summary.Duration += result.Duration
+		switch result.Status {
+		case "passed":
+			htmlReport.Summary.Passed++
+		case "failed":
+			if result.Quarantined {
+				htmlReport.Summary.Quarantined++
+			} else {
+				htmlReport.Summary.Failed++
+			}
+		case "warning":
+			htmlReport.Summary.Warning++
+		case "processing":
+			htmlReport.Summary.Processing++
+		}
+
+		if _, ok := browserIndex[result.Browser]; !ok {
+			browserIndex[result.Browser] = len(htmlReport.Browsers)
+			htmlReport.Browsers = append(htmlReport.Browsers, result.Browser)
+		}
+		label := fmt.Sprintf("%s (%s, %s)", result.Feature.Name, result.Site.Name, result.Device.Name)
+		row, ok := rowIndex[label]
+		if !ok {
+			row = len(htmlReport.Grid)
+			rowIndex[label] = row
+			htmlReport.Grid = append(htmlReport.Grid, HTMLGridRow{Label: label})
+			cells = append(cells, make(map[int]HTMLGridCell))
+		}
+		column := browserIndex[result.Browser]
+		if cell, ok := cells[row][column]; !ok || result.CreatedAt.After(cell.createdAt) {
+			cells[row][column] = HTMLGridCell{Status: result.Status, ResultID: result.ID, createdAt: result.CreatedAt}
+		}
+
+		htmlResult := HTMLResult{
+			ID:              result.ID,
+			Site:            result.Site.Name,
+			Device:          result.Device.Name,
+			Feature:         result.Feature.Name,
+			Browser:         result.Browser,
+			Status:          result.Status,
+			Duration:        result.Duration,
+			CreatedAt:       result.CreatedAt,
+			ErrorLog:        result.ErrorLog,
+			FailureCategory: result.FailureCategory,
+			Quarantined:     result.Quarantined,
+			PassedOnRetry:   result.PassedOnRetry,
+		}
+		for _, detail := range result.Details {
+			htmlResult.Steps = append(htmlResult.Steps, HTMLStep{
+				Time:        detail.CreatedAt,
+				Description: detail.Description,
+				Screenshot:  screenshotURL(detail.Screenshot, options),
+			})
+		}
+		htmlReport.Results = append(htmlReport.Results, htmlResult)
+	}
+
+	// Fill the cells of browsers a row has no result for
+	for row := range htmlReport.Grid {
+		htmlReport.Grid[row].Cells = make([]HTMLGridCell, len(htmlReport.Browsers))
+		for column, cell := range cells[row] {
+			htmlReport.Grid[row].Cells[column] = cell
+		}
+	}
+
+	return htmlReport
+}
+
+// screenshotURL returns the screenshot as a data URL when embedding, falling back to a link
+// when the file cannot be read. Only files in the screenshots directory are inlined.
+func screenshotURL(path string, options HTMLOptions) template.URL {
+	if path == "" {
+		return ""
+	}
+
+	link := template.URL(strings.TrimSuffix(options.BaseURL, "/") + "/" + strings.TrimPrefix(path, "/"))
+	if !options.EmbedScreenshots {
+		return link
+	}
+
+	cleaned, ok := LocalScreenshot(path)
+	if !ok {
+		return link
+	}
+
+	var mimeType string
+	switch strings.ToLower(filepath.Ext(cleaned)) {
+	case ".png":
+		mimeType = "image/png"
+	case ".jpg", ".jpeg":
+		mimeType = "image/jpeg"
+	default:
+		return link
+	}
+
+	data, err := os.ReadFile(cleaned)
+	if err != nil {
+		return link
+	}
+
+	return template.URL("data:" + mimeType + ";base64," + base64.StdEncoding.EncodeToString(data))
+}
+
+// LocalScreenshot returns the cleaned file path of a screenshot saved by the runner, and whether the
+// path points into the screenshots directory. Other paths are never read from disk.
+func LocalScreenshot(path string) (string, bool) {
+	cleaned := filepath.Clean(path)
+	if filepath.IsAbs(cleaned) || filepath.Dir(cleaned) != screenshotsDir {
+		return "", false
+	}
+	return cleaned, true
+}
+
+// WriteHTML renders the report as a self-contained HTML document
+func WriteHTML(w io.Writer, report HTMLReport) error {
+	return htmlTemplate.Execute(w, report)
+}
@@ -0,0 +1,220 @@
+package report
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"qa-automation-system/backend/models"
+)
+
+// JUnitTestSuites is the root element of a JUnit XML report
+type JUnitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr,omitempty"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Skipped  int              `xml:"skipped,attr"`
+	Time     string           `xml:"time,attr"`
+	Suites   []JUnitTestSuite `xml:"testsuite"`
+}
+
+// JUnitTestSuite is a group of testcases, such as the results of one browser in a run
+type JUnitTestSuite struct {
+	XMLName   xml.Name        `xml:"testsuite"`
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Skipped   int             `xml:"skipped,attr"`
+	Time      string          `xml:"time,attr"`
+	Timestamp string          `xml:"timestamp,attr,omitempty"`
+	Cases     []JUnitTestCase `xml:"testcase"`
+
+	seconds float64
+}
+
+// JUnitTestCase is a single result
+type JUnitTestCase struct {
+	XMLName   xml.Name      `xml:"testcase"`
+	Name      string        `xml:"name,attr"`
+	Classname string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *JUnitFailure `xml:"failure,omitempty"`
+	Skipped   *JUnitSkipped `xml:"skipped,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+	SystemErr string        `xml:"system-err,omitempty"`
+}
+
+// JUnitFailure carries the error log of a failed result, typed by its failure category
+type JUnitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// JUnitSkipped marks a result that has not finished
+type JUnitSkipped struct {
+	Message string `xml:"message,attr"`
+}
+
+// JUnitTotals are the outcome counts of a suite or report
+type JUnitTotals struct {
+	Tests    int
+	Failures int
+	Skipped  int
+	Time     float64
+}
+
+// formatSeconds formats a duration in seconds as JUnit expects
+func formatSeconds(seconds float64) string {
+	return strconv.FormatFloat(seconds, 'f', 3, 64)
+}
+
+// NewJUnitTestCase maps a result, with its site, device, feature and details loaded, to a testcase.
+// Failed results carry the error log as failure, processing and quarantined failed ones are
+// skipped, and the warnings and retry note of a passed result go to system-err.
+func NewJUnitTestCase(result models.Result) JUnitTestCase {
+	testCase := JUnitTestCase{
+		Name:      fmt.Sprintf("%s (%s)", result.Feature.Name, result.Device.Name),
+		Classname: strings.ReplaceAll(result.Site.Name, ".", "_") + "." + result.Browser,
+		Time:      formatSeconds(result.Duration),
+	}
+
+	var steps []string
+	for _, detail := range result.Details {
+		step := fmt.Sprintf("[%s] %s", detail.CreatedAt.Format("15:04:05"), detail.Description)
+		if detail.Screenshot != "" {
+			step += " (screenshot: " + detail.Screenshot + ")"
+		}
+		steps = append(steps, step)
+	}
+	testCase.SystemOut = strings.Join(steps, "\n")
+
+	switch {
+	case result.Status == "failed" && result.Quarantined:
+		testCase.Skipped = &JUnitSkipped{Message: "failure of a quarantined combination"}
+		testCase.SystemErr = result.ErrorLog
+	case result.Status == "failed":
+		message := result.ErrorLog
+		if index := strings.Index(message, "\n"); index != -1 {
+			message = message[:index]
+		}
+		failureType := result.FailureCategory
+		if failureType == "" {
+			failureType = "failed"
+		}
+		testCase.Failure = &JUnitFailure{Message: message, Type: failureType, Text: result.ErrorLog}
+	case result.Status == "processing":
+		testCase.Skipped = &JUnitSkipped{Message: "test is still processing"}
+	case result.Status == "warning" || result.PassedOnRetry:
+		testCase.SystemErr = result.ErrorLog
+	}
+
+	return testCase
+}
+
+// Add counts a testcase in the totals
+func (t *JUnitTotals) Add(testCase JUnitTestCase, seconds float64) {
+	t.Tests++
+	if testCase.Failure != nil {
+		t.Failures++
+	}
+	if testCase.Skipped != nil {
+		t.Skipped++
+	}
+	t.Time += seconds
+}
+
+// NewJUnitTestSuite maps the results to a suite of testcases
+func NewJUnitTestSuite(name string, timestamp time.Time, results []models.Result) JUnitTestSuite {
+	var totals JUnitTotals
+	suite := JUnitTestSuite{Name: name, Timestamp: timestamp.Format("2006-01-02T15:04:05")}
+	for _, result := range results {
+		testCase := NewJUnitTestCase(result)
+		totals.Add(testCase, result.Duration)
+		suite.Cases = append(suite.Cases, testCase)
+	}
+
+	suite.Tests = totals.Tests
+	suite.Failures = totals.Failures
+	suite.Skipped = totals.Skipped
+	suite.Time = formatSeconds(totals.Time)
+	suite.seconds = totals.Time
+
+	return suite
+}
+
+// WriteJUnit writes the report as an indented XML document
+func WriteJUnit(w io.Writer, report JUnitTestSuites) error {
+	seconds := 0.0
+	for _, suite := range report.Suites {
+		report.Tests += suite.Tests
+		report.Failures += suite.Failures
+		report.Skipped += suite.Skipped
+		seconds += suite.seconds
+	}
+	report.Time = formatSeconds(seconds)
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(report); err != nil {
+		return err
+	}
+
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// JUnitWriter writes a report of one suite a testcase at a time, for reports too large to hold in memory
+type JUnitWriter struct {
+	encoder *xml.Encoder
+}
+
+// NewJUnitWriter starts a report of one suite with the totals known beforehand
+func NewJUnitWriter(w io.Writer, name string, totals JUnitTotals) (*JUnitWriter, error) {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return nil, err
+	}
+
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+
+	attrs := []xml.Attr{
+		{Name: xml.Name{Local: "name"}, Value: name},
+		{Name: xml.Name{Local: "tests"}, Value: strconv.Itoa(totals.Tests)},
+		{Name: xml.Name{Local: "failures"}, Value: strconv.Itoa(totals.Failures)},
+		{Name: xml.Name{Local: "skipped"}, Value: strconv.Itoa(totals.Skipped)},
+		{Name: xml.Name{Local: "time"}, Value: formatSeconds(totals.Time)},
+	}
+	if err := encoder.EncodeToken(xml.StartElement{Name: xml.Name{Local: "testsuites"}, Attr: attrs}); err != nil {
+		return nil, err
+	}
+	if err := encoder.EncodeToken(xml.StartElement{Name: xml.Name{Local: "testsuite"}, Attr: attrs}); err != nil {
+		return nil, err
+	}
+
+	return &JUnitWriter{encoder: encoder}, nil
+}
+
+// WriteTestCase appends a testcase to the suite
+func (jw *JUnitWriter) WriteTestCase(testCase JUnitTestCase) error {
+	return jw.encoder.Encode(testCase)
+}
+
+// Close ends the suite and the report
+func (jw *JUnitWriter) Close() error {
+	if err := jw.encoder.EncodeToken(xml.EndElement{Name: xml.Name{Local: "testsuite"}}); err != nil {
+		return err
+	}
+	if err := jw.encoder.EncodeToken(xml.EndElement{Name: xml.Name{Local: "testsuites"}}); err != nil {
+		return err
+	}
+
+	return jw.encoder.Flush()
+}
@@ -0,0 +1,229 @@
+package testrunner
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"qa-automation-system/backend/models"
+)
+
+// defaultAxeCorePath is the vendored axe-core script, relative to the backend directory
+const defaultAxeCorePath = "assets/axe.min.js"
+
+// axeCoreURL is the pinned axe-core build to vendor
+const axeCoreURL = "https://cdn.jsdelivr.net/npm/axe-core@4.10.2/axe.min.js"
+
+// impactRank orders the axe-core impact levels from least to most severe
+var impactRank = map[string]int{
+	"minor":    1,
+	"moderate": 2,
+	"serious":  3,
+	"critical": 4,
+}
+
+// axeRunScript runs axe-core on the current document and returns its violations
+const axeRunScript = `
+	var done = arguments[arguments.length - 1];
+	if (typeof axe === 'undefined') {
+		done({ error: 'axe-core is not loaded' });
+		return;
+	}
+	axe.run(document, { resultTypes: ['violations'] }).then(function(results) {
+		done({
+			violations: results.violations.map(function(violation) {
+				return {
+					id: violation.id,
+					impact: violation.impact || '',
+					help: violation.help,
+					helpUrl: violation.helpUrl,
+					nodes: violation.nodes.map(function(node) {
+						return { target: node.target.map(String) };
+					})
+				};
+			})
+		});
+	}).catch(function(e) {
+		done({ error: String(e) });
+	});
+`
+
+// AxeViolation is an accessibility rule violation reported by axe-core
+type AxeViolation struct {
+	ID      string `json:"id"`
+	Impact  string `json:"impact"`
+	Help    string `json:"help"`
+	HelpURL string `json:"helpUrl"`
+	Nodes   []struct {
+		Target []string `json:"target"`
+	} `json:"nodes"`
+}
+
+// accessibilityPages returns the pages visited by the site flows, relative to the site root
+func accessibilityPages(siteName string) []string {
+	pages := []string{"/", "/chat"}
+	if siteName == "shorts.senti.live" || siteName == "viblys.com" {
+		pages = append(pages, "/store")
+	}
+	return pages
+}
+
+// loadAxeCore reads the vendored axe-core script, honoring the AXE_CORE_PATH override. The script
+// is injected into logged-in pages, so it is never downloaded at run time, and when AXE_CORE_SHA256
+// is set a script with another digest is refused.
+func loadAxeCore() (string, error) {
+	path := os.Getenv("AXE_CORE_PATH")
+	if path == "" {
+		path = defaultAxeCorePath
+	}
+
+	script, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return "", fmt.Errorf("axe-core script not found at %s, vendor %s there (see assets/README.md)", path, axeCoreURL)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read axe-core script at %s: %v", path, err)
+	}
+
+	if want := strings.ToLower(strings.TrimSpace(os.Getenv("AXE_CORE_SHA256"))); want != "" {
+		sum := sha256.Sum256(script)
+		if got := hex.EncodeToString(sum[:]); got != want {
+			return "", fmt.Errorf("axe-core script at %s has sha256 %s, expected %s", path, got, want)
+		}
+	}
+
+	return string(script), nil
+}
+
+// accessibilityStatus returns the status for the most severe impact found, based on the
+// A11Y_FAIL_IMPACT (default serious) and A11Y_WARN_IMPACT (default moderate) thresholds
+func accessibilityStatus(impacts []string) string {
+	failImpact := os.Getenv("A11Y_FAIL_IMPACT")
+	if impactRank[failImpact] == 0 {
+		failImpact = "serious"
+	}
+	warnImpact := os.Getenv("A11Y_WARN_IMPACT")
+	if impactRank[warnImpact] == 0 {
+		warnImpact = "moderate"
+	}
+
+	status := "passed"
+	for _, impact := range impacts {
+		rank := impactRank[impact]
+		if rank >= impactRank[failImpact] {
+			return "failed"
+		}
+		if rank >= impactRank[warnImpact] {
+			status = "warning"
+		}
+	}
+
+	return status
+}
+
+// RunAxe injects axe-core into the current page and returns its violations
+func (r *BrowserStackRunner) RunAxe(axeScript string) ([]AxeViolation, error) {
+	if r.driver == nil {
+		return nil, fmt.Errorf("driver not initialized")
+	}
+
+	if _, err := r.driver.ExecuteScript(axeScript, nil); err != nil {
+		return nil, fmt.Errorf("failed to inject axe-core: %v", err)
+	}
+
+	if err := r.driver.SetAsyncScriptTimeout(30 * time.Second); err != nil {
+		return nil, fmt.Errorf("failed to set script timeout: %v", err)
+	}
+
+	response, err := r.driver.ExecuteScriptAsyncRaw(axeRunScript, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to run axe-core: %v", err)
+	}
+
+	var reply struct {
+		Value struct {
+			Violations []AxeViolation `json:"violations"`
+			Error      string         `json:"error"`
+		} `json:"value"`
+	}
+	if err := json.Unmarshal(response, &reply); err != nil {
+		return nil, fmt.Errorf("failed to parse axe-core results: %v", err)
+	}
+	if reply.Value.Error != "" {
+		return nil, fmt.Errorf("axe-core failed: %s", reply.Value.Error)
+	}
+
+	return reply.Value.Violations, nil
+}
+
+// Accessibility Audit
+func (r *BrowserStackRunner) AccessibilityAudit(siteName string, featureName string, browserType string, resultID uint, db *gorm.DB) error {
+	if r.driver == nil {
+		return fmt.Errorf("driver not initialized")
+	}
+
+	axeScript, err := loadAxeCore()
+	if err != nil {
+		return err
+	}
+
+	var impacts []string
+	violationCount := 0
+
+	for _, path := range accessibilityPages(siteName) {
+		pageURL := "https://" + siteName + strings.TrimSuffix(path, "/")
+		if err := r.driver.Get(pageURL); err != nil {
+			return fmt.Errorf("failed to navigate to %s: %v", pageURL, err)
+		}
+
+		// Wait for the page to load
+		time.Sleep(3 * time.Second)
+
+		violations, err := r.RunAxe(axeScript)
+		if err != nil {
+			return fmt.Errorf("failed to audit %s: %v", pageURL, err)
+		}
+
+		for _, violation := range violations {
+			impacts = append(impacts, violation.Impact)
+			for _, node := range violation.Nodes {
+				finding := models.ResultFinding{
+					ResultID: resultID,
+					Type:     models.FindingTypeAccessibility,
+					Page:     pageURL,
+					Rule:     violation.ID,
+					Impact:   violation.Impact,
+					Selector: strings.Join(node.Target, " "),
+					URL:      violation.HelpURL,
+					Message:  violation.Help,
+				}
+				if err := db.Create(&finding).Error; err != nil {
+					log.Printf("Warning: Failed to store accessibility finding for %s: %v", browserType, err)
+				}
+			}
+			violationCount++
+		}
+
+		if err := r.LogTestStep(fmt.Sprintf("%d accessibility violations found on %s", len(violations), pageURL)); err != nil {
+			log.Printf("Warning: Failed to log accessibility audit for %s: %v", browserType, err)
+		}
+
+		// Take screenshot of audited page
+		r.TakeStepScreenshot(db, resultID, browserType, fmt.Sprintf("%s of %s", featureName, pageURL))
+	}
+
+	switch accessibilityStatus(impacts) {
+	case "failed":
+		return fmt.Errorf("%d accessibility violations found, at least one at or above the failing impact", violationCount)
+	case "warning":
+		r.addWarning(fmt.Sprintf("%d accessibility violations found below the failing impact", violationCount))
+	}
+
+	return nil
+}
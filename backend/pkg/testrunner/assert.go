@@ -0,0 +1,201 @@
+package testrunner
+
+import (
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/tebeka/selenium"
+	"gorm.io/gorm"
+	"qa-automation-system/backend/models"
+)
+
+// defaultAssertTimeout is how long an assertion waits for its condition by default
+const defaultAssertTimeout = 5 * time.Second
+
+// Asserter checks conditions on the current page. Hard assertions return their failure
+// as an error; soft assertions record it as a warning and let the test continue.
+type Asserter struct {
+	runner  *BrowserStackRunner
+	soft    bool
+	timeout time.Duration
+}
+
+// AssertionFailure is a failed assertion recorded during a test
+type AssertionFailure struct {
+	Name     string
+	Selector string
+	Message  string
+	Soft     bool
+}
+
+// Expect returns a hard asserter for the current page
+func (r *BrowserStackRunner) Expect() *Asserter {
+	return &Asserter{runner: r, timeout: defaultAssertTimeout}
+}
+
+// SoftExpect returns a soft asserter for the current page
+func (r *BrowserStackRunner) SoftExpect() *Asserter {
+	return &Asserter{runner: r, soft: true, timeout: defaultAssertTimeout}
+}
+
+// Within returns a copy of the asserter that waits up to timeout for its conditions
+func (a *Asserter) Within(timeout time.Duration) *Asserter {
+	scoped := *a
+	scoped.timeout = timeout
+	return &scoped
+}
+
+// ElementVisible asserts that an element matching the selector is displayed
+func (a *Asserter) ElementVisible(selector string) error {
+	return a.check("element_visible", selector, fmt.Sprintf("expected %s to be visible", selector), func(wd selenium.WebDriver) (bool, error) {
+		elements, err := wd.FindElements(selenium.ByCSSSelector, selector)
+		if err != nil {
+			return false, nil
+		}
+		for _, element := range elements {
+			if displayed, err := element.IsDisplayed(); err == nil && displayed {
+				return true, nil
+			}
+		}
+		return false, nil
+	})
+}
+
+// TextContains asserts that an element matching the selector contains the text, ignoring case
+func (a *Asserter) TextContains(selector, text string) error {
+	return a.check("text_contains", selector, fmt.Sprintf("expected %s to contain %q", selector, text), func(wd selenium.WebDriver) (bool, error) {
+		elements, err := wd.FindElements(selenium.ByCSSSelector, selector)
+		if err != nil {
+			return false, nil
+		}
+		for _, element := range elements {
+			if content, err := element.Text(); err == nil && strings.Contains(strings.ToLower(content), strings.ToLower(text)) {
+				return true, nil
+			}
+		}
+		return false, nil
+	})
+}
+
+// TextEquals asserts that an element matching the selector has the whole text. The comparison
+// ignores case, as sites style the same label in different cases.
+func (a *Asserter) TextEquals(selector, text string) error {
+	return a.check("text_equals", selector, fmt.Sprintf("expected %s to be %q ignoring case", selector, text), func(wd selenium.WebDriver) (bool, error) {
+		elements, err := wd.FindElements(selenium.ByCSSSelector, selector)
+		if err != nil {
+			return false, nil
+		}
+		for _, element := range elements {
+			if content, err := element.Text(); err == nil && strings.EqualFold(content, text) {
+				return true, nil
+			}
+		}
+		return false, nil
+	})
+}
+
+// URLMatches asserts that the current URL matches the regular expression
+func (a *Asserter) URLMatches(pattern string) error {
+	expression, err := regexp.Compile(pattern)
+	if err != nil {
+		return fmt.Errorf("invalid URL pattern %s: %v", pattern, err)
+	}
+
+	return a.check("url_matches", "", fmt.Sprintf("expected URL to match %s", pattern), func(wd selenium.WebDriver) (bool, error) {
+		currentURL, err := wd.CurrentURL()
+		if err != nil {
+			return false, nil
+		}
+		return expression.MatchString(currentURL), nil
+	})
+}
+
+// AttributeEquals asserts that the first element matching the selector has the attribute value
+func (a *Asserter) AttributeEquals(selector, attribute, expected string) error {
+	return a.check("attribute_equals", selector, fmt.Sprintf("expected %s[%s] to equal %q", selector, attribute, expected), func(wd selenium.WebDriver) (bool, error) {
+		element, err := wd.FindElement(selenium.ByCSSSelector, selector)
+		if err != nil {
+			return false, nil
+		}
+		value, err := element.GetAttribute(attribute)
+		if err != nil {
+			return false, nil
+		}
+		return value == expected, nil
+	})
+}
+
+// ElementCount asserts that exactly count elements match the selector
+func (a *Asserter) ElementCount(selector string, count int) error {
+	return a.check("element_count", selector, fmt.Sprintf("expected %d elements matching %s", count, selector), func(wd selenium.WebDriver) (bool, error) {
+		elements, err := wd.FindElements(selenium.ByCSSSelector, selector)
+		if err != nil {
+			return false, nil
+		}
+		return len(elements) == count, nil
+	})
+}
+
+// ElementCountAtLeast asserts that at least min elements match the selector
+func (a *Asserter) ElementCountAtLeast(selector string, min int) error {
+	return a.check("element_count", selector, fmt.Sprintf("expected at least %d elements matching %s", min, selector), func(wd selenium.WebDriver) (bool, error) {
+		elements, err := wd.FindElements(selenium.ByCSSSelector, selector)
+		if err != nil {
+			return false, nil
+		}
+		return len(elements) >= min, nil
+	})
+}
+
+// check waits for the condition and records the failure according to the asserter mode
+func (a *Asserter) check(name, selector, expectation string, condition selenium.Condition) error {
+	r := a.runner
+	if r.driver == nil {
+		return fmt.Errorf("driver not initialized")
+	}
+
+	if err := r.driver.WaitWithTimeoutAndInterval(condition, a.timeout, 250*time.Millisecond); err == nil {
+		return nil
+	}
+
+	message := fmt.Sprintf("assertion failed: %s within %v", expectation, a.timeout)
+	r.assertionFailures = append(r.assertionFailures, AssertionFailure{
+		Name:     name,
+		Selector: selector,
+		Message:  message,
+		Soft:     a.soft,
+	})
+
+	if a.soft {
+		r.addWarning(message)
+		return nil
+	}
+
+	return fmt.Errorf("%s", message)
+}
+
+// storeAssertionFailures saves the failed assertions of a result as findings
+func (r *BrowserStackRunner) storeAssertionFailures(db *gorm.DB, resultID uint) {
+	for _, failure := range r.assertionFailures {
+		impact := "hard"
+		if failure.Soft {
+			impact = "soft"
+		}
+
+		finding := models.ResultFinding{
+			ResultID: resultID,
+			Type:     models.FindingTypeAssertion,
+			Rule:     failure.Name,
+			Impact:   impact,
+			Selector: failure.Selector,
+			Message:  failure.Message,
+		}
+		if err := db.Create(&finding).Error; err != nil {
+			log.Printf("Warning: Failed to store assertion failure for Result ID %d: %v", resultID, err)
+		}
+	}
+	r.assertionFailures = nil
+}
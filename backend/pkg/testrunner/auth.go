@@ -0,0 +1,261 @@
+package testrunner
+
+import (
+	"fmt"
+	"log"
+	"regexp"
+	"time"
+
+	"github.com/tebeka/selenium"
+	"gorm.io/gorm"
+)
+
+const (
+	// loginButtonSelector is the login button shown on the public pages
+	loginButtonSelector = ".login-text"
+	// loginSubmitSelector is the submit button of the login form
+	loginSubmitSelector = "#btn-register"
+	// loginErrorSelector matches the field messages, alerts and snackbars the login form shows errors in
+	loginErrorSelector = ".v-messages__message, .v-alert, .v-snack__content, .v-snackbar__content, [role='alert']"
+	// loginTimeout is how long the login form has to respond
+	loginTimeout = 15 * time.Second
+	// logoutXPath matches a control labelled logout, log out or sign out
+	logoutXPath = `//*[self::a or self::button or @role='menuitem' or @role='button' or contains(@class, 'v-list-item')]` +
+		`[translate(normalize-space(.), 'ABCDEFGHIJKLMNOPQRSTUVWXYZ', 'abcdefghijklmnopqrstuvwxyz') = 'logout' or ` +
+		`translate(normalize-space(.), 'ABCDEFGHIJKLMNOPQRSTUVWXYZ', 'abcdefghijklmnopqrstuvwxyz') = 'log out' or ` +
+		`translate(normalize-space(.), 'ABCDEFGHIJKLMNOPQRSTUVWXYZ', 'abcdefghijklmnopqrstuvwxyz') = 'sign out']`
+)
+
+// authSelectors are the login markup of a site
+type authSelectors struct {
+	// LoggedIn is an element only shown to logged-in users, such as the account menu
+	LoggedIn string
+	// Logout is the logout control, found by its label when empty
+	Logout string
+	// LoginError is the message expected for a wrong password, any login error is accepted when empty
+	LoginError string
+}
+
+// siteAuthSelectors are the built-in login markup of each site
+var siteAuthSelectors = map[string]authSelectors{
+	"senti.live":        {LoggedIn: ".v-app-bar .v-avatar"},
+	"shorts.senti.live": {LoggedIn: ".v-app-bar .v-avatar"},
+	"hothinge.com":      {LoggedIn: ".v-app-bar .v-avatar"},
+	"viblys.com":        {LoggedIn: ".v-app-bar .v-avatar"},
+}
+
+// authSelectorsFor returns the login markup of a site, each part overridden by <SITE>_LOGGED_IN_SELECTOR,
+// <SITE>_LOGOUT_SELECTOR and <SITE>_LOGIN_ERROR_TEXT such as VIBLYS_LOGGED_IN_SELECTOR
+func authSelectorsFor(siteName string) (authSelectors, error) {
+	defaults := siteAuthSelectors[siteName]
+	selectors := authSelectors{
+		LoggedIn:   siteSetting(siteName, "LOGGED_IN_SELECTOR", defaults.LoggedIn),
+		Logout:     siteSetting(siteName, "LOGOUT_SELECTOR", defaults.Logout),
+		LoginError: siteSetting(siteName, "LOGIN_ERROR_TEXT", defaults.LoginError),
+	}
+	if selectors.LoggedIn == "" {
+		return selectors, fmt.Errorf("logged-in marker not known for site: %s", siteName)
+	}
+	return selectors, nil
+}
+
+// loginPageURL returns the URL pattern of the login page of a site
+func loginPageURL(siteName string) string {
+	return "^https://" + regexp.QuoteMeta(siteName) + "/login"
+}
+
+// AssertLoggedIn asserts the page shows a logged-in user: the login button is gone and the
+// site's element only shown to logged-in users is visible
+func (r *BrowserStackRunner) AssertLoggedIn(siteName string, assert *Asserter) error {
+	selectors, err := authSelectorsFor(siteName)
+	if err != nil {
+		return err
+	}
+
+	if err := assert.ElementCount(loginButtonSelector, 0); err != nil {
+		return err
+	}
+
+	return assert.ElementVisible(selectors.LoggedIn)
+}
+
+// ValidLogin verifies the login performed at the start of the test shows the logged-in page
+func (r *BrowserStackRunner) ValidLogin(siteName string, featureName string, browserType string, resultID uint, db *gorm.DB) error {
+	if r.driver == nil {
+		return fmt.Errorf("driver not initialized")
+	}
+
+	if err := r.AssertLoggedIn(siteName, r.Expect().Within(loginTimeout)); err != nil {
+		return err
+	}
+
+	if err := r.LogTestStep(fmt.Sprintf("Logged-in page shown for %s", browserType)); err != nil {
+		log.Printf("Warning: Failed to log valid login for %s: %v", browserType, err)
+	}
+
+	return nil
+}
+
+// InvalidPassword submits the login form with a wrong password and verifies the form
+// shows an error and keeps the user on the login page
+func (r *BrowserStackRunner) InvalidPassword(siteName string, email string, featureName string, browserType string, resultID uint, db *gorm.DB) error {
+	if r.driver == nil {
+		return fmt.Errorf("driver not initialized")
+	}
+
+	selectors, err := authSelectorsFor(siteName)
+	if err != nil {
+		return err
+	}
+
+	password := fmt.Sprintf("invalid-%d", time.Now().UnixNano())
+	if err := r.NavigateToLoginPage(siteName, email, password); err != nil {
+		return err
+	}
+
+	submitButton, err := r.driver.FindElement(selenium.ByCSSSelector, loginSubmitSelector)
+	if err != nil {
+		return fmt.Errorf("failed to find submit button: %v", err)
+	}
+	if err := submitButton.Click(); err != nil {
+		return fmt.Errorf("failed to click submit button: %v", err)
+	}
+
+	// Check the site's message for a wrong password, or any error message when it has none
+	assert := r.Expect().Within(loginTimeout)
+	if selectors.LoginError != "" {
+		err = assert.TextContains(loginErrorSelector, selectors.LoginError)
+	} else {
+		err = assert.ElementVisible(loginErrorSelector)
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := r.Expect().URLMatches(loginPageURL(siteName)); err != nil {
+		return err
+	}
+
+	if err := r.LogTestStep(fmt.Sprintf("Login rejected with a wrong password for %s", browserType)); err != nil {
+		log.Printf("Warning: Failed to log invalid password for %s: %v", browserType, err)
+	}
+
+	// Take screenshot of the login error
+	r.TakeStepScreenshot(db, resultID, browserType, featureName)
+
+	return nil
+}
+
+// findLogoutControl finds the site's logout control, from its selector or by its label,
+// opening the account menu of the logged-in marker when the control is hidden in it
+func (r *BrowserStackRunner) findLogoutControl(selectors authSelectors) (selenium.WebElement, error) {
+	find := func() (selenium.WebElement, error) {
+		if selectors.Logout != "" {
+			return r.driver.FindElement(selenium.ByCSSSelector, selectors.Logout)
+		}
+		elements, err := r.driver.FindElements(selenium.ByXPATH, logoutXPath)
+		if err != nil {
+			return nil, err
+		}
+		for _, element := range elements {
+			if displayed, err := element.IsDisplayed(); err == nil && displayed {
+				return element, nil
+			}
+		}
+		return nil, fmt.Errorf("no visible logout control")
+	}
+
+	if element, err := find(); err == nil {
+		return element, nil
+	}
+
+	marker := selectors.LoggedIn
+	menu, err := r.findContainer(marker)
+	if err != nil {
+		return nil, err
+	}
+	if err := menu.Click(); err != nil {
+		return nil, fmt.Errorf("failed to open account menu %s: %v", marker, err)
+	}
+	time.Sleep(1 * time.Second)
+
+	element, err := find()
+	if err != nil {
+		return nil, fmt.Errorf("logout control not found in account menu %s: %v", marker, err)
+	}
+
+	return element, nil
+}
+
+// Logout logs out of the site and verifies the public page is shown and stays public after a reload
+func (r *BrowserStackRunner) Logout(siteName string, featureName string, browserType string, resultID uint, db *gorm.DB) error {
+	if r.driver == nil {
+		return fmt.Errorf("driver not initialized")
+	}
+
+	selectors, err := authSelectorsFor(siteName)
+	if err != nil {
+		return err
+	}
+
+	control, err := r.findLogoutControl(selectors)
+	if err != nil {
+		return err
+	}
+	if err := control.Click(); err != nil {
+		return fmt.Errorf("failed to click logout control: %v", err)
+	}
+
+	if err := r.Expect().Within(loginTimeout).ElementVisible(loginButtonSelector); err != nil {
+		return err
+	}
+
+	// The session must be gone, not only hidden by the page
+	if err := r.driver.Refresh(); err != nil {
+		return fmt.Errorf("failed to reload page: %v", err)
+	}
+	if err := r.Expect().Within(loginTimeout).ElementVisible(loginButtonSelector); err != nil {
+		return err
+	}
+
+	if err := r.LogTestStep(fmt.Sprintf("Logged out to the public page for %s", browserType)); err != nil {
+		log.Printf("Warning: Failed to log logout for %s: %v", browserType, err)
+	}
+
+	// Take screenshot of the public page
+	r.TakeStepScreenshot(db, resultID, browserType, featureName)
+
+	return nil
+}
+
+// SessionPersistence reloads the page and verifies the user is still logged in
+func (r *BrowserStackRunner) SessionPersistence(siteName string, featureName string, browserType string, resultID uint, db *gorm.DB) error {
+	if r.driver == nil {
+		return fmt.Errorf("driver not initialized")
+	}
+
+	if err := r.driver.Refresh(); err != nil {
+		return fmt.Errorf("failed to reload page: %v", err)
+	}
+	time.Sleep(2 * time.Second)
+
+	currentURL, err := r.driver.CurrentURL()
+	if err != nil {
+		return fmt.Errorf("failed to get current URL: %v", err)
+	}
+	if regexp.MustCompile(loginPageURL(siteName)).MatchString(currentURL) {
+		return fmt.Errorf("session lost after reload: redirected to %s", currentURL)
+	}
+	if err := r.AssertLoggedIn(siteName, r.Expect().Within(loginTimeout)); err != nil {
+		return fmt.Errorf("session lost after reload: %v", err)
+	}
+
+	if err := r.LogTestStep(fmt.Sprintf("Session kept after reload for %s", browserType)); err != nil {
+		log.Printf("Warning: Failed to log session persistence for %s: %v", browserType, err)
+	}
+
+	// Take screenshot of the reloaded page
+	r.TakeStepScreenshot(db, resultID, browserType, featureName)
+
+	return nil
+}
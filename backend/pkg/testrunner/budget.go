@@ -0,0 +1,166 @@
+package testrunner
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"gorm.io/gorm"
+	"qa-automation-system/backend/models"
+)
+
+const (
+	// regressionWindow is the number of previous samples the rolling median is computed from
+	regressionWindow = 10
+	// regressionMinSamples is the number of previous samples required before regressions are flagged
+	regressionMinSamples = 3
+	// regressionTolerance is the relative slowdown over the median that counts as a regression
+	regressionTolerance = 0.3
+)
+
+// regressionMinDelta is the absolute slowdown a metric must also exceed, so that
+// small pages do not get flagged for a few milliseconds of noise
+var regressionMinDelta = map[string]float64{
+	models.MetricTimeToFirstByte:        100,
+	models.MetricDOMContentLoaded:       250,
+	models.MetricLoadEventEnd:           250,
+	models.MetricFirstPaint:             250,
+	models.MetricFirstContentfulPaint:   250,
+	models.MetricLargestContentfulPaint: 250,
+	models.MetricCumulativeLayoutShift:  0.05,
+}
+
+// CheckBudgets returns the status and messages for the metrics that exceed a budget.
+// The status is "failed" if any exceeded budget has that severity, "warning" otherwise.
+func CheckBudgets(metrics []models.PageMetric, budgets []models.PerformanceBudget) (string, []string) {
+	status := "passed"
+	var messages []string
+
+	for _, metric := range metrics {
+		for _, budget := range budgets {
+			if budget.Page != metric.Page {
+				continue
+			}
+			value, ok := metric.Value(budget.Metric)
+			if !ok || value <= budget.Threshold {
+				continue
+			}
+
+			messages = append(messages, fmt.Sprintf("Performance budget exceeded on %s: %s %s > %s (%s)",
+				metric.Page, budget.Metric, formatMetric(budget.Metric, value), formatMetric(budget.Metric, budget.Threshold), budget.Severity))
+
+			if budget.Severity == "failed" {
+				status = "failed"
+			} else if status == "passed" {
+				status = "warning"
+			}
+		}
+	}
+
+	return status, messages
+}
+
+// FailedBudgetMessages returns the messages of CheckBudgets for the exceeded budgets of failed severity
+func FailedBudgetMessages(messages []string) []string {
+	var failed []string
+	for _, message := range messages {
+		if strings.HasPrefix(message, "Performance budget exceeded on ") && strings.HasSuffix(message, "(failed)") {
+			failed = append(failed, message)
+		}
+	}
+	return failed
+}
+
+// CheckRegression compares a metric value against the rolling median of previous values
+// and returns a message when it is significantly slower
+func CheckRegression(page, name string, value float64, history []float64) (string, bool) {
+	if len(history) < regressionMinSamples {
+		return "", false
+	}
+
+	baseline := median(history)
+	if value-baseline < regressionMinDelta[name] || value <= baseline*(1+regressionTolerance) {
+		return "", false
+	}
+
+	increase := 100.0
+	if baseline > 0 {
+		increase = (value - baseline) / baseline * 100
+	}
+
+	return fmt.Sprintf("Performance regression on %s: %s %s vs median %s of last %d runs (+%.0f%%)",
+		page, name, formatMetric(name, value), formatMetric(name, baseline), len(history), increase), true
+}
+
+// EvaluatePerformance checks the stored page metrics of a result against the site budgets
+// and the previous runs on the same browser. It returns the status the result should end
+// with and the messages to record in its error log.
+func (r *BrowserStackRunner) EvaluatePerformance(db *gorm.DB, siteID, resultID uint) (string, []string, error) {
+	var metrics []models.PageMetric
+	if err := db.Where("result_id = ?", resultID).Order("id ASC").Find(&metrics).Error; err != nil {
+		return "passed", nil, fmt.Errorf("failed to load page metrics: %v", err)
+	}
+	if len(metrics) == 0 {
+		return "passed", nil, nil
+	}
+
+	var budgets []models.PerformanceBudget
+	if err := db.Where("site_id = ?", siteID).Find(&budgets).Error; err != nil {
+		return "passed", nil, fmt.Errorf("failed to load performance budgets: %v", err)
+	}
+
+	status, messages := CheckBudgets(metrics, budgets)
+
+	for _, metric := range metrics {
+		var previous []models.PageMetric
+		if err := db.Model(&models.PageMetric{}).
+			Joins("JOIN results ON results.id = page_metrics.result_id").
+			Where("results.site_id = ? AND results.browser = ? AND page_metrics.page = ? AND page_metrics.result_id < ?",
+				siteID, r.browserType, metric.Page, resultID).
+			Order("page_metrics.id DESC").
+			Limit(regressionWindow).
+			Find(&previous).Error; err != nil {
+			return status, messages, fmt.Errorf("failed to load previous page metrics: %v", err)
+		}
+
+		for _, name := range models.PageMetricNames {
+			value, ok := metric.Value(name)
+			if !ok {
+				continue
+			}
+
+			var history []float64
+			for _, p := range previous {
+				if v, ok := p.Value(name); ok {
+					history = append(history, v)
+				}
+			}
+
+			if message, regressed := CheckRegression(metric.Page, name, value, history); regressed {
+				messages = append(messages, message)
+			}
+		}
+	}
+
+	return status, messages, nil
+}
+
+// median returns the median of the values
+func median(values []float64) float64 {
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+
+	middle := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[middle-1] + sorted[middle]) / 2
+	}
+	return sorted[middle]
+}
+
+// formatMetric formats a metric value with its unit
+func formatMetric(name string, value float64) string {
+	if name == models.MetricCumulativeLayoutShift {
+		return fmt.Sprintf("%.3f", value)
+	}
+	return fmt.Sprintf("%.0fms", value)
+}
@@ -0,0 +1,199 @@
+package testrunner
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/tebeka/selenium"
+)
+
+// chatMessageTimeout is how long the sent message has to appear in the conversation
+const chatMessageTimeout = 20 * time.Second
+
+// defaultChatMessageList is the conversation container of the chat rest pages
+const defaultChatMessageList = ".chat-rest-messages"
+
+// chatMessageList returns the conversation container of a site, overridden by
+// <SITE>_CHAT_MESSAGE_LIST such as VIBLYS_CHAT_MESSAGE_LIST
+func chatMessageList(siteName string) string {
+	return siteSetting(siteName, "CHAT_MESSAGE_LIST", defaultChatMessageList)
+}
+
+// chatSendHookScript starts timing the sending of a message: it notes the time and hooks fetch,
+// XMLHttpRequest and WebSocket, once per page, to note when the server answers the request
+// carrying the message text or, over a socket, sends back a frame carrying it
+const chatSendHookScript = `
+	window.__qaChatSend = { text: arguments[0], sentAt: performance.now(), ackAt: null };
+	if (window.__qaChatHooked) {
+		return;
+	}
+	window.__qaChatHooked = true;
+
+	function pending(body) {
+		var send = window.__qaChatSend;
+		if (send && send.ackAt === null && typeof body === 'string' && body.indexOf(send.text) !== -1) {
+			return send;
+		}
+		return null;
+	}
+	function ack(send) {
+		if (send.ackAt === null) {
+			send.ackAt = performance.now();
+		}
+	}
+
+	if (window.fetch) {
+		var originalFetch = window.fetch;
+		window.fetch = function(input, init) {
+			var response = originalFetch.apply(this, arguments);
+			var send = pending(init && init.body);
+			if (send) {
+				response.then(function() { ack(send); }, function() {});
+			}
+			return response;
+		};
+	}
+
+	var originalXHRSend = XMLHttpRequest.prototype.send;
+	XMLHttpRequest.prototype.send = function(body) {
+		var send = pending(body);
+		if (send) {
+			this.addEventListener('load', function() { ack(send); });
+		}
+		return originalXHRSend.apply(this, arguments);
+	};
+
+	if (window.WebSocket) {
+		var originalWSSend = WebSocket.prototype.send;
+		WebSocket.prototype.send = function(data) {
+			var send = pending(data);
+			var socket = this;
+			if (send) {
+				socket.addEventListener('message', function echo(event) {
+					if (typeof event.data === 'string' && event.data.indexOf(send.text) !== -1) {
+						ack(send);
+						socket.removeEventListener('message', echo);
+					}
+				});
+			}
+			return originalWSSend.apply(this, arguments);
+		};
+	}
+`
+
+// chatRoundTripScript returns the milliseconds between sending the message and the server
+// answer, or null when no answer was seen yet
+const chatRoundTripScript = `
+	var send = window.__qaChatSend;
+	if (!send || send.ackAt === null) {
+		return null;
+	}
+	return send.ackAt - send.sentAt;
+`
+
+// chatReplyScript finds the message row holding the sent text in the message list and returns
+// the text of the first row after it, or null when no reply has arrived yet
+const chatReplyScript = `
+	var text = arguments[0];
+	var list = document.querySelector(arguments[1]);
+	if (!list) {
+		return null;
+	}
+	var match = null;
+	var walker = document.createTreeWalker(list, NodeFilter.SHOW_ELEMENT);
+	while (walker.nextNode()) {
+		var node = walker.currentNode;
+		if (node.textContent.indexOf(text) !== -1) {
+			match = node;
+		}
+	}
+	if (!match) {
+		return null;
+	}
+
+	// Climb to the row that sits in a list of sibling messages
+	var row = match;
+	while (row !== list && row.parentElement !== list && row.parentElement.children.length < 2) {
+		row = row.parentElement;
+	}
+
+	var next = row.nextElementSibling;
+	while (next) {
+		var reply = (next.innerText || '').trim();
+		if (reply !== '' && reply.indexOf(text) === -1) {
+			return reply;
+		}
+		next = next.nextElementSibling;
+	}
+	return null;
+`
+
+// chatReplyTimeout returns how long to wait for the counterpart reply, from CHAT_REPLY_TIMEOUT in seconds
+func chatReplyTimeout() time.Duration {
+	return time.Duration(envInt("CHAT_REPLY_TIMEOUT", 30)) * time.Second
+}
+
+// waitForChatRoundTrip waits for the server to answer the sent message and returns how long it
+// took, or zero when no request or socket frame carrying the message was seen within the timeout
+func (r *BrowserStackRunner) waitForChatRoundTrip(timeout time.Duration) (time.Duration, error) {
+	if r.driver == nil {
+		return 0, fmt.Errorf("driver not initialized")
+	}
+
+	var roundTrip time.Duration
+	err := r.driver.WaitWithTimeoutAndInterval(func(wd selenium.WebDriver) (bool, error) {
+		value, err := wd.ExecuteScript(chatRoundTripScript, nil)
+		if err != nil {
+			return false, err
+		}
+		if milliseconds, ok := value.(float64); ok {
+			roundTrip = time.Duration(milliseconds * float64(time.Millisecond))
+			return true, nil
+		}
+		return false, nil
+	}, timeout, 250*time.Millisecond)
+	if err != nil && isWaitTimeout(err) {
+		return 0, nil
+	}
+
+	return roundTrip, err
+}
+
+// waitForChatReply waits for a message to appear after the sent one in the message list and
+// returns its text, or an empty string when no reply arrived within the timeout
+func (r *BrowserStackRunner) waitForChatReply(listSelector, message string, timeout time.Duration) (string, error) {
+	if r.driver == nil {
+		return "", fmt.Errorf("driver not initialized")
+	}
+	if timeout <= 0 {
+		return "", nil
+	}
+
+	reply := ""
+	err := r.driver.WaitWithTimeoutAndInterval(func(wd selenium.WebDriver) (bool, error) {
+		value, err := wd.ExecuteScript(chatReplyScript, []interface{}{message, listSelector})
+		if err != nil {
+			return false, err
+		}
+		if text, ok := value.(string); ok && text != "" {
+			reply = text
+			return true, nil
+		}
+		return false, nil
+	}, timeout, time.Second)
+	if err != nil && reply == "" {
+		// A timeout only means the counterpart did not answer
+		if isWaitTimeout(err) {
+			return "", nil
+		}
+		return "", err
+	}
+
+	return reply, nil
+}
+
+// isWaitTimeout reports whether a selenium wait ended because its timeout elapsed
+func isWaitTimeout(err error) bool {
+	return err != nil && strings.HasPrefix(err.Error(), "timeout after")
+}
@@ -0,0 +1,43 @@
+package testrunner
+
+import (
+	"regexp"
+
+	"qa-automation-system/backend/models"
+)
+
+// failurePattern maps error log text to a failure category
+type failurePattern struct {
+	category string
+	pattern  *regexp.Regexp
+}
+
+// failurePatterns are matched in order against an error log, the first match wins. The failures
+// of the Asserter come first, as their expectations quote selectors and texts of the site. The
+// causes outside the site come next so that, for example, a timeout of the WebDriver connection
+// counts as infrastructure and not as a slow page.
+var failurePatterns = []failurePattern{
+	{models.FailureAssertion, regexp.MustCompile(`(?i)^assertion failed`)},
+	{models.FailureNotImplemented, regexp.MustCompile(`(?i)not been implemented|not implemented`)},
+	{models.FailureInfrastructure, regexp.MustCompile(`(?i)webdriver|browserstack|driver not initialized|` +
+		`session not created|invalid session id|no such (window|session)|failed to initialize|` +
+		`connection (refused|reset)|broken pipe|unexpected EOF|\b50[234]\b|bad gateway|service unavailable`)},
+	{models.FailureCredentials, regexp.MustCompile(`(?i)still on login page|credential|invalid (email|password)|` +
+		`wrong password|unauthori[sz]ed|\b401\b|account (locked|disabled)`)},
+	{models.FailureTimeout, regexp.MustCompile(`(?i)timed? ?out|deadline exceeded`)},
+	{models.FailureSelectorNotFound, regexp.MustCompile(`(?i)no such element|unable to locate|failed to find|` +
+		`not found|no visible|stale element`)},
+	{models.FailureNavigation, regexp.MustCompile(`(?i)navigat|not on .* page|failed to load|redirect|` +
+		`failed to (get current url|reload)|session lost|ERR_[A-Z_]+`)},
+}
+
+// ClassifyFailure returns the failure category of an error log. Failures that match no known
+// cause are assertions: the test ran and found the site behaving differently than expected.
+func ClassifyFailure(errorLog string) string {
+	for _, pattern := range failurePatterns {
+		if pattern.pattern.MatchString(errorLog) {
+			return pattern.category
+		}
+	}
+	return models.FailureAssertion
+}
@@ -0,0 +1,42 @@
+package testrunner
+
+import (
+	"testing"
+
+	"qa-automation-system/backend/models"
+)
+
+func TestClassifyFailure(t *testing.T) {
+	tests := []struct {
+		errorLog string
+		want     string
+	}{
+		// Asserter failures, with and without Within
+		{"assertion failed: expected 0 elements matching .login-text within 0s", models.FailureAssertion},
+		{"assertion failed: expected .v-app-bar .v-avatar to be visible within 10s", models.FailureAssertion},
+		{`assertion failed: expected p to be "age verification" within 5s`, models.FailureAssertion},
+		{`assertion failed: expected .v-messages__message, .v-alert to contain "invalid password" within 10s`, models.FailureAssertion},
+		{"assertion failed: expected URL to match ^https://viblys.com/login within 10s", models.FailureAssertion},
+		{"Performance budget exceeded on /chat: largest_contentful_paint 4.20s > 2.50s (failed)", models.FailureAssertion},
+
+		// auth.go
+		{"login failed: still on login page after 10s", models.FailureCredentials},
+		{"session lost after reload: redirected to https://senti.live/login", models.FailureNavigation},
+		{"logout control not found in account menu .v-avatar: no such element: Unable to locate element", models.FailureSelectorNotFound},
+
+		// runner
+		{"failed to initialize WebDriver: unexpected EOF", models.FailureInfrastructure},
+		{"driver not initialized", models.FailureInfrastructure},
+		{"Chat test has not been implemented yet for hothinge.com", models.FailureNotImplemented},
+		{"failed to find .login-text button: no such element", models.FailureSelectorNotFound},
+		{"navigation failed: not on home page, current URL: https://senti.live/", models.FailureNavigation},
+		{"timeout after 20s: condition not met", models.FailureTimeout},
+		{"scroll 2: no new feed items loaded within 10s", models.FailureAssertion},
+	}
+
+	for _, tt := range tests {
+		if got := ClassifyFailure(tt.errorLog); got != tt.want {
+			t.Errorf("ClassifyFailure(%q) = %s, want %s", tt.errorLog, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,206 @@
+package testrunner
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// feedContainerSelector is the scroll container of the video feed sites
+	feedContainerSelector = ".video-feed"
+	// feedLoadTimeout is how long new feed items have to appear after a scroll
+	feedLoadTimeout = 15 * time.Second
+	// feedWheelDelta is how far one scroll moves the feed, in pixels
+	feedWheelDelta = 800
+	// feedSwipeDelta is how far one swipe up moves the feed on touch devices, in pixels
+	feedSwipeDelta = 400
+)
+
+// feedItemsScript lists the items of the feed container with an identifier for each and
+// whether the item is still an empty placeholder
+const feedItemsScript = `
+	var container = document.querySelector(arguments[0]);
+	if (!container) {
+		return null;
+	}
+
+	// Descend through wrappers until reaching the list of items
+	var list = container;
+	while (list.children.length === 1) {
+		list = list.children[0];
+	}
+
+	var items = [];
+	for (var i = 0; i < list.children.length; i++) {
+		var item = list.children[i];
+		var media = item.querySelector('video, img');
+		var link = item.querySelector('a[href]');
+		var id = item.getAttribute('data-id') || item.getAttribute('data-video-id') || item.getAttribute('data-key') || item.id ||
+			(media && (media.currentSrc || media.getAttribute('src'))) || (link && link.getAttribute('href')) || '';
+		var placeholder = /skeleton|placeholder|loading/i.test(item.className || '') ||
+			(!media && (item.innerText || '').trim() === '');
+		items.push({ id: id, placeholder: placeholder });
+	}
+	return items;
+`
+
+// FeedItem is an item of the infinite-scroll feed
+type FeedItem struct {
+	ID          string `json:"id"`
+	Placeholder bool   `json:"placeholder"`
+}
+
+// FeedItems reads the items currently rendered in the feed container
+func (r *BrowserStackRunner) FeedItems(selector string) ([]FeedItem, error) {
+	if r.driver == nil {
+		return nil, fmt.Errorf("driver not initialized")
+	}
+
+	response, err := r.driver.ExecuteScriptRaw(feedItemsScript, []interface{}{selector})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read feed items: %v", err)
+	}
+
+	var reply struct {
+		Value *[]FeedItem `json:"value"`
+	}
+	if err := json.Unmarshal(response, &reply); err != nil {
+		return nil, fmt.Errorf("failed to parse feed items: %v", err)
+	}
+	if reply.Value == nil {
+		_, err := r.findContainer(selector)
+		return nil, err
+	}
+
+	return *reply.Value, nil
+}
+
+// checkFeedItems returns an error for duplicate item IDs or placeholders that never filled in
+func checkFeedItems(items []FeedItem) error {
+	seen := make(map[string]bool, len(items))
+	var duplicates []string
+	placeholders := 0
+
+	for _, item := range items {
+		if item.Placeholder {
+			placeholders++
+			continue
+		}
+		if item.ID == "" {
+			continue
+		}
+		if seen[item.ID] {
+			duplicates = append(duplicates, item.ID)
+		}
+		seen[item.ID] = true
+	}
+
+	if len(duplicates) > 0 {
+		return fmt.Errorf("feed contains duplicate items: %s", strings.Join(duplicates, ", "))
+	}
+	if placeholders > 0 {
+		return fmt.Errorf("feed contains %d empty placeholders after loading", placeholders)
+	}
+
+	return nil
+}
+
+// feedScrollCount returns how many times the feed is scrolled, from FEED_SCROLL_COUNT
+func feedScrollCount() int {
+	return envInt("FEED_SCROLL_COUNT", 5)
+}
+
+// InfiniteScrollFeed scrolls the video feed several times, swiping on touch devices, and verifies
+// that each scroll loads new, unique and filled-in items, recording how long every page of items took
+func (r *BrowserStackRunner) InfiniteScrollFeed(siteName string, deviceName string, featureName string, browserType string, resultID uint, db *gorm.DB) error {
+	if r.driver == nil {
+		return fmt.Errorf("driver not initialized")
+	}
+
+	if siteName != "shorts.senti.live" && siteName != "viblys.com" {
+		return fmt.Errorf("%s is not a video feed site", siteName)
+	}
+
+	items, err := r.FeedItems(feedContainerSelector)
+	if err != nil {
+		return err
+	}
+	if len(items) == 0 {
+		return fmt.Errorf("feed %s has no items", feedContainerSelector)
+	}
+
+	loaded := make(map[string]bool)
+	for _, item := range items {
+		if item.ID != "" {
+			loaded[item.ID] = true
+		}
+	}
+	initial := len(loaded)
+
+	scrolls := feedScrollCount()
+	for i := 1; i <= scrolls; i++ {
+		if deviceName == "Mobile" || deviceName == "Tablet" {
+			err = r.Swipe(feedContainerSelector, 0, -feedSwipeDelta, 300*time.Millisecond)
+		} else {
+			err = r.Wheel(feedContainerSelector, 0, feedWheelDelta)
+		}
+		if err != nil {
+			return err
+		}
+		scrolledAt := time.Now()
+
+		// Wait for items that were not in the feed before and for the placeholders to fill in
+		newItems := 0
+		for {
+			items, err = r.FeedItems(feedContainerSelector)
+			if err != nil {
+				return err
+			}
+
+			newItems = 0
+			pending := false
+			for _, item := range items {
+				if item.Placeholder {
+					pending = true
+				} else if item.ID != "" && !loaded[item.ID] {
+					newItems++
+				}
+			}
+			if (newItems > 0 && !pending) || time.Since(scrolledAt) > feedLoadTimeout {
+				break
+			}
+			time.Sleep(250 * time.Millisecond)
+		}
+
+		if err := checkFeedItems(items); err != nil {
+			r.TakeStepScreenshot(db, resultID, browserType, fmt.Sprintf("Feed Scroll %d", i))
+			return fmt.Errorf("scroll %d: %v", i, err)
+		}
+
+		if newItems == 0 {
+			r.TakeStepScreenshot(db, resultID, browserType, fmt.Sprintf("Feed Scroll %d", i))
+			return fmt.Errorf("scroll %d: no new feed items loaded within %v", i, feedLoadTimeout)
+		}
+
+		r.recordMetric(fmt.Sprintf("Feed Page %d Load Time", i), float64(time.Since(scrolledAt).Milliseconds()), "ms")
+		for _, item := range items {
+			if item.ID != "" {
+				loaded[item.ID] = true
+			}
+		}
+	}
+
+	if err := r.LogTestStep(fmt.Sprintf("Scrolled the feed %d times, %d new items loaded", scrolls, len(loaded)-initial)); err != nil {
+		log.Printf("Warning: Failed to log feed scroll for %s: %v", browserType, err)
+	}
+
+	// Take screenshot of the scrolled feed
+	r.TakeStepScreenshot(db, resultID, browserType, featureName)
+
+	return nil
+}
@@ -0,0 +1,181 @@
+package testrunner
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/tebeka/selenium"
+)
+
+// SwitchToFrame waits for an iframe matching the selector and switches the session into it
+func (r *BrowserStackRunner) SwitchToFrame(selector string, timeout time.Duration) error {
+	if r.driver == nil {
+		return fmt.Errorf("driver not initialized")
+	}
+
+	var frame selenium.WebElement
+	err := r.driver.WaitWithTimeoutAndInterval(func(wd selenium.WebDriver) (bool, error) {
+		element, err := wd.FindElement(selenium.ByCSSSelector, selector)
+		if err != nil {
+			return false, nil
+		}
+		frame = element
+		return true, nil
+	}, timeout, 250*time.Millisecond)
+	if err != nil {
+		return fmt.Errorf("failed to find iframe %s within %v", selector, timeout)
+	}
+
+	if err := r.driver.SwitchFrame(frame); err != nil {
+		return fmt.Errorf("failed to switch to iframe %s: %v", selector, err)
+	}
+
+	return nil
+}
+
+// SwitchToFrameIndex switches the session into the iframe at the given index of the current document
+func (r *BrowserStackRunner) SwitchToFrameIndex(index int) error {
+	if r.driver == nil {
+		return fmt.Errorf("driver not initialized")
+	}
+
+	frames, err := r.driver.FindElements(selenium.ByTagName, "iframe")
+	if err != nil {
+		return fmt.Errorf("failed to find iframes: %v", err)
+	}
+	if index < 0 || index >= len(frames) {
+		return fmt.Errorf("iframe index %d out of range, %d iframes on page", index, len(frames))
+	}
+
+	if err := r.driver.SwitchFrame(frames[index]); err != nil {
+		return fmt.Errorf("failed to switch to iframe %d: %v", index, err)
+	}
+
+	return nil
+}
+
+// SwitchToDefaultContent switches the session back to the top-level document
+func (r *BrowserStackRunner) SwitchToDefaultContent() error {
+	if r.driver == nil {
+		return fmt.Errorf("driver not initialized")
+	}
+
+	if err := r.driver.SwitchFrame(nil); err != nil {
+		return fmt.Errorf("failed to switch to default content: %v", err)
+	}
+
+	return nil
+}
+
+// WithinFrame runs fn inside the iframe matching the selector and always returns to the top-level document
+func (r *BrowserStackRunner) WithinFrame(selector string, timeout time.Duration, fn func() error) error {
+	if err := r.SwitchToFrame(selector, timeout); err != nil {
+		return err
+	}
+
+	fnErr := fn()
+	if err := r.SwitchToDefaultContent(); err != nil && fnErr == nil {
+		return err
+	}
+
+	return fnErr
+}
+
+// WindowHandles returns the handles of the open windows and tabs
+func (r *BrowserStackRunner) WindowHandles() ([]string, error) {
+	if r.driver == nil {
+		return nil, fmt.Errorf("driver not initialized")
+	}
+
+	handles, err := r.driver.WindowHandles()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get window handles: %v", err)
+	}
+
+	return handles, nil
+}
+
+// WaitForNewWindow waits for a window or tab that is not in existing and returns its handle
+func (r *BrowserStackRunner) WaitForNewWindow(existing []string, timeout time.Duration) (string, error) {
+	known := make(map[string]bool, len(existing))
+	for _, handle := range existing {
+		known[handle] = true
+	}
+
+	deadline := time.Now().Add(timeout)
+	for {
+		handles, err := r.WindowHandles()
+		if err != nil {
+			return "", err
+		}
+		for _, handle := range handles {
+			if !known[handle] {
+				return handle, nil
+			}
+		}
+
+		if time.Now().After(deadline) {
+			return "", fmt.Errorf("no new window opened within %v", timeout)
+		}
+		time.Sleep(250 * time.Millisecond)
+	}
+}
+
+// SwitchToWindow switches the session to the window or tab with the handle
+func (r *BrowserStackRunner) SwitchToWindow(handle string) error {
+	if r.driver == nil {
+		return fmt.Errorf("driver not initialized")
+	}
+
+	if err := r.driver.SwitchWindow(handle); err != nil {
+		return fmt.Errorf("failed to switch to window %s: %v", handle, err)
+	}
+
+	return nil
+}
+
+// CloseWindow closes the window or tab with the handle and switches back to returnTo
+func (r *BrowserStackRunner) CloseWindow(handle, returnTo string) error {
+	if err := r.SwitchToWindow(handle); err != nil {
+		return err
+	}
+
+	if err := r.driver.CloseWindow(handle); err != nil {
+		return fmt.Errorf("failed to close window %s: %v", handle, err)
+	}
+
+	return r.SwitchToWindow(returnTo)
+}
+
+// canvasLoadedScript reports whether the document has a visible, non-empty canvas
+const canvasLoadedScript = `
+	var canvases = document.querySelectorAll('canvas');
+	for (var i = 0; i < canvases.length; i++) {
+		var rect = canvases[i].getBoundingClientRect();
+		if (canvases[i].width > 0 && canvases[i].height > 0 && rect.width > 0 && rect.height > 0) {
+			return true;
+		}
+	}
+	return false;
+`
+
+// WaitForCanvas waits until the current document renders a visible canvas
+func (r *BrowserStackRunner) WaitForCanvas(timeout time.Duration) error {
+	if r.driver == nil {
+		return fmt.Errorf("driver not initialized")
+	}
+
+	err := r.driver.WaitWithTimeoutAndInterval(func(wd selenium.WebDriver) (bool, error) {
+		loaded, err := wd.ExecuteScript(canvasLoadedScript, nil)
+		if err != nil {
+			return false, nil
+		}
+		ok, _ := loaded.(bool)
+		return ok, nil
+	}, timeout, 500*time.Millisecond)
+	if err != nil {
+		return fmt.Errorf("no visible game canvas loaded within %v", timeout)
+	}
+
+	return nil
+}
@@ -0,0 +1,414 @@
+package testrunner
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	slog "github.com/tebeka/selenium/log"
+	"gorm.io/gorm"
+	"qa-automation-system/backend/models"
+)
+
+// HAR represents an HTTP Archive (HAR 1.2) document
+type HAR struct {
+	Log HARLog `json:"log"`
+}
+
+// HARLog is the root object of a HAR document
+type HARLog struct {
+	Version string     `json:"version"`
+	Creator HARCreator `json:"creator"`
+	Entries []HAREntry `json:"entries"`
+}
+
+// HARCreator describes the application that created the HAR document
+type HARCreator struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+// HAREntry represents a single request/response pair
+type HAREntry struct {
+	StartedDateTime string      `json:"startedDateTime"`
+	Time            float64     `json:"time"`
+	Request         HARRequest  `json:"request"`
+	Response        HARResponse `json:"response"`
+	Cache           struct{}    `json:"cache"`
+	Timings         HARTimings  `json:"timings"`
+	ResourceType    string      `json:"_resourceType,omitempty"`
+	Error           string      `json:"_error,omitempty"`
+}
+
+// HARRequest holds the request part of a HAR entry
+type HARRequest struct {
+	Method      string      `json:"method"`
+	URL         string      `json:"url"`
+	HTTPVersion string      `json:"httpVersion"`
+	Cookies     []HARHeader `json:"cookies"`
+	Headers     []HARHeader `json:"headers"`
+	QueryString []HARHeader `json:"queryString"`
+	HeadersSize int         `json:"headersSize"`
+	BodySize    int         `json:"bodySize"`
+}
+
+// HARResponse holds the response part of a HAR entry
+type HARResponse struct {
+	Status      int         `json:"status"`
+	StatusText  string      `json:"statusText"`
+	HTTPVersion string      `json:"httpVersion"`
+	Cookies     []HARHeader `json:"cookies"`
+	Headers     []HARHeader `json:"headers"`
+	Content     HARContent  `json:"content"`
+	RedirectURL string      `json:"redirectURL"`
+	HeadersSize int         `json:"headersSize"`
+	BodySize    int         `json:"bodySize"`
+}
+
+// HARHeader is a name/value pair used for headers, cookies and query strings
+type HARHeader struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// HARContent describes the response body
+type HARContent struct {
+	Size     int    `json:"size"`
+	MimeType string `json:"mimeType"`
+}
+
+// HARTimings holds the timing phases of a HAR entry in milliseconds
+type HARTimings struct {
+	Send    float64 `json:"send"`
+	Wait    float64 `json:"wait"`
+	Receive float64 `json:"receive"`
+}
+
+// FailedRequest summarizes a request that returned an error status or never completed
+type FailedRequest struct {
+	Method string  `json:"method"`
+	URL    string  `json:"url"`
+	Status int     `json:"status"`
+	Reason string  `json:"reason"`
+	Error  string  `json:"error,omitempty"`
+	Time   float64 `json:"time"`
+}
+
+// cdpEvent is a Chrome DevTools Protocol event as reported in the performance log
+type cdpEvent struct {
+	Message struct {
+		Method string          `json:"method"`
+		Params json.RawMessage `json:"params"`
+	} `json:"message"`
+}
+
+// cdpResponse is the response object of the Network domain events
+type cdpResponse struct {
+	URL               string            `json:"url"`
+	Status            int               `json:"status"`
+	StatusText        string            `json:"statusText"`
+	Headers           map[string]string `json:"headers"`
+	MimeType          string            `json:"mimeType"`
+	Protocol          string            `json:"protocol"`
+	EncodedDataLength float64           `json:"encodedDataLength"`
+}
+
+// cdpNetworkParams holds the fields used from the Network domain events
+type cdpNetworkParams struct {
+	RequestID string  `json:"requestId"`
+	Timestamp float64 `json:"timestamp"`
+	WallTime  float64 `json:"wallTime"`
+	Type      string  `json:"type"`
+	Request   struct {
+		URL     string            `json:"url"`
+		Method  string            `json:"method"`
+		Headers map[string]string `json:"headers"`
+	} `json:"request"`
+	RedirectResponse  *cdpResponse `json:"redirectResponse"`
+	Response          cdpResponse  `json:"response"`
+	EncodedDataLength float64      `json:"encodedDataLength"`
+	ErrorText         string       `json:"errorText"`
+	Canceled          bool         `json:"canceled"`
+}
+
+// pendingRequest tracks a request while its events are being collected
+type pendingRequest struct {
+	entry     HAREntry
+	startedAt float64
+	headersAt float64
+}
+
+// harLoggingPrefsKey returns the capability key that enables the performance log for a browser
+func harLoggingPrefsKey(browserType string) string {
+	switch browserType {
+	case "chrome":
+		return slog.CapabilitiesKey
+	case "edge":
+		return "ms:loggingPrefs"
+	}
+	return ""
+}
+
+// BuildHAR converts the DevTools network events of a performance log into a HAR document
+func BuildHAR(messages []slog.Message) *HAR {
+	har := &HAR{
+		Log: HARLog{
+			Version: "1.2",
+			Creator: HARCreator{Name: "QA Automation System", Version: "1.0"},
+			Entries: []HAREntry{},
+		},
+	}
+
+	pending := make(map[string]*pendingRequest)
+	var order []*pendingRequest
+
+	finish := func(p *pendingRequest, timestamp float64) {
+		if timestamp > p.startedAt {
+			p.entry.Time = (timestamp - p.startedAt) * 1000
+		}
+		if p.headersAt > p.startedAt {
+			p.entry.Timings.Wait = (p.headersAt - p.startedAt) * 1000
+			p.entry.Timings.Receive = p.entry.Time - p.entry.Timings.Wait
+		} else {
+			p.entry.Timings.Wait = p.entry.Time
+		}
+	}
+
+	for _, message := range messages {
+		var event cdpEvent
+		if err := json.Unmarshal([]byte(message.Message), &event); err != nil {
+			continue
+		}
+		if !strings.HasPrefix(event.Message.Method, "Network.") {
+			continue
+		}
+
+		var params cdpNetworkParams
+		if err := json.Unmarshal(event.Message.Params, &params); err != nil {
+			continue
+		}
+
+		switch event.Message.Method {
+		case "Network.requestWillBeSent":
+			// A redirect reuses the request ID, so close the previous hop first
+			if p, ok := pending[params.RequestID]; ok && params.RedirectResponse != nil {
+				p.entry.Response = harResponse(*params.RedirectResponse)
+				p.entry.Response.RedirectURL = params.Request.URL
+				finish(p, params.Timestamp)
+				delete(pending, params.RequestID)
+			}
+
+			startedAt := time.Unix(0, int64(params.WallTime*float64(time.Second)))
+			p := &pendingRequest{
+				entry: HAREntry{
+					StartedDateTime: startedAt.UTC().Format(time.RFC3339Nano),
+					Request: HARRequest{
+						Method:      params.Request.Method,
+						URL:         params.Request.URL,
+						HTTPVersion: "HTTP/1.1",
+						Cookies:     []HARHeader{},
+						Headers:     harHeaders(params.Request.Headers),
+						QueryString: harQueryString(params.Request.URL),
+						HeadersSize: -1,
+						BodySize:    -1,
+					},
+					Response: HARResponse{
+						Cookies:     []HARHeader{},
+						Headers:     []HARHeader{},
+						HeadersSize: -1,
+						BodySize:    -1,
+					},
+					ResourceType: params.Type,
+				},
+				startedAt: params.Timestamp,
+			}
+			pending[params.RequestID] = p
+			order = append(order, p)
+		case "Network.responseReceived":
+			if p, ok := pending[params.RequestID]; ok {
+				p.entry.Response = harResponse(params.Response)
+				p.headersAt = params.Timestamp
+			}
+		case "Network.loadingFinished":
+			if p, ok := pending[params.RequestID]; ok {
+				p.entry.Response.BodySize = int(params.EncodedDataLength)
+				p.entry.Response.Content.Size = int(params.EncodedDataLength)
+				finish(p, params.Timestamp)
+				delete(pending, params.RequestID)
+			}
+		case "Network.loadingFailed":
+			if p, ok := pending[params.RequestID]; ok {
+				p.entry.Error = params.ErrorText
+				if params.Canceled && p.entry.Error == "" {
+					p.entry.Error = "canceled"
+				}
+				finish(p, params.Timestamp)
+				delete(pending, params.RequestID)
+			}
+		}
+	}
+
+	// Requests without a final event were still in flight when the session ended
+	for _, p := range pending {
+		if p.entry.Response.Status == 0 && p.entry.Error == "" {
+			p.entry.Error = "no response before the capture ended"
+		}
+	}
+
+	for _, p := range order {
+		har.Log.Entries = append(har.Log.Entries, p.entry)
+	}
+
+	return har
+}
+
+// harResponse converts a DevTools response into a HAR response
+func harResponse(response cdpResponse) HARResponse {
+	httpVersion := strings.ToUpper(response.Protocol)
+	if httpVersion == "" {
+		httpVersion = "HTTP/1.1"
+	}
+
+	return HARResponse{
+		Status:      response.Status,
+		StatusText:  response.StatusText,
+		HTTPVersion: httpVersion,
+		Cookies:     []HARHeader{},
+		Headers:     harHeaders(response.Headers),
+		Content: HARContent{
+			MimeType: response.MimeType,
+		},
+		HeadersSize: -1,
+		BodySize:    -1,
+	}
+}
+
+// harHeaders converts a header map into HAR name/value pairs
+func harHeaders(headers map[string]string) []HARHeader {
+	result := []HARHeader{}
+	for name, value := range headers {
+		result = append(result, HARHeader{Name: name, Value: value})
+	}
+	return result
+}
+
+// harQueryString extracts the query parameters of a URL as HAR name/value pairs
+func harQueryString(rawURL string) []HARHeader {
+	result := []HARHeader{}
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return result
+	}
+	for name, values := range parsed.Query() {
+		for _, value := range values {
+			result = append(result, HARHeader{Name: name, Value: value})
+		}
+	}
+	return result
+}
+
+// LoadHAR reads a HAR document from disk
+func LoadHAR(path string) (*HAR, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read HAR file: %v", err)
+	}
+
+	var har HAR
+	if err := json.Unmarshal(content, &har); err != nil {
+		return nil, fmt.Errorf("failed to parse HAR file: %v", err)
+	}
+
+	return &har, nil
+}
+
+// FailedRequests returns the entries of a HAR document that failed with a 4xx/5xx status,
+// timed out or never received a response
+func FailedRequests(har *HAR) []FailedRequest {
+	failed := []FailedRequest{}
+	for _, entry := range har.Log.Entries {
+		reason := ""
+		switch {
+		case strings.Contains(entry.Error, "TIMED_OUT"):
+			reason = "timeout"
+		case entry.Error == "canceled" || strings.Contains(entry.Error, "ERR_ABORTED"):
+			// Requests aborted by the page itself are not failures
+			continue
+		case entry.Error != "":
+			reason = "network_error"
+		case entry.Response.Status >= 500:
+			reason = "server_error"
+		case entry.Response.Status >= 400:
+			reason = "client_error"
+		default:
+			continue
+		}
+
+		failed = append(failed, FailedRequest{
+			Method: entry.Request.Method,
+			URL:    entry.Request.URL,
+			Status: entry.Response.Status,
+			Reason: reason,
+			Error:  entry.Error,
+			Time:   entry.Time,
+		})
+	}
+
+	return failed
+}
+
+// SaveHAR collects the network events of the session and stores them as a HAR artifact of the result
+func (r *BrowserStackRunner) SaveHAR(db *gorm.DB, resultID uint) (string, error) {
+	if r.driver == nil {
+		return "", fmt.Errorf("driver not initialized")
+	}
+
+	if harLoggingPrefsKey(r.browserType) == "" {
+		return "", fmt.Errorf("HAR capture is not supported on %s", r.browserType)
+	}
+
+	messages, err := r.driver.Log(slog.Performance)
+	if err != nil {
+		return "", fmt.Errorf("failed to read performance log: %v", err)
+	}
+
+	content, err := json.MarshalIndent(BuildHAR(messages), "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to encode HAR: %v", err)
+	}
+
+	// Create artifacts directory if it doesn't exist
+	if err := os.MkdirAll("artifacts", 0755); err != nil {
+		return "", fmt.Errorf("failed to create artifacts directory: %v", err)
+	}
+
+	filename := fmt.Sprintf("artifacts/network_%d_%s.har", resultID, time.Now().Format("20060102_150405"))
+	if err := os.WriteFile(filename, content, 0644); err != nil {
+		return "", fmt.Errorf("failed to save HAR: %v", err)
+	}
+
+	artifact := models.ResultArtifact{
+		ResultID: resultID,
+		Type:     models.ArtifactTypeHAR,
+		Path:     filename,
+	}
+	if err := db.Create(&artifact).Error; err != nil {
+		return "", fmt.Errorf("failed to store HAR artifact: %v", err)
+	}
+
+	return filename, nil
+}
+
+// captureHAR saves the HAR artifact of a result, logging instead of failing the test
+func (r *BrowserStackRunner) captureHAR(db *gorm.DB, resultID uint) {
+	filename, err := r.SaveHAR(db, resultID)
+	if err != nil {
+		log.Printf("Warning: Failed to capture HAR for Result ID %d: %v", resultID, err)
+		return
+	}
+	log.Printf("HAR saved for Result ID %d: %s", resultID, filename)
+}
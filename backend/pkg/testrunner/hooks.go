@@ -0,0 +1,263 @@
+package testrunner
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/tebeka/selenium"
+	"gorm.io/gorm"
+	"qa-automation-system/backend/models"
+)
+
+// HookContext is what a hook knows about the feature test it runs around
+type HookContext struct {
+	DB       *gorm.DB
+	Site     models.Site
+	Feature  models.Feature
+	Email    string
+	ResultID uint
+}
+
+// Hook is a setup or teardown step of a feature
+type Hook struct {
+	Name string
+	Kind string
+	Run  func(r *BrowserStackRunner, hc HookContext) error
+}
+
+// FeatureHooks are the hooks run before and after the test of a feature
+type FeatureHooks struct {
+	Before []Hook
+	After  []Hook
+}
+
+// UIHook creates a hook that drives the browser session of the test
+func UIHook(name string, steps func(r *BrowserStackRunner, hc HookContext) error) Hook {
+	return Hook{Name: name, Kind: models.HookKindUI, Run: steps}
+}
+
+// FuncHook creates a hook that runs Go code without touching the browser
+func FuncHook(name string, fn func(hc HookContext) error) Hook {
+	return Hook{Name: name, Kind: models.HookKindFunc, Run: func(r *BrowserStackRunner, hc HookContext) error {
+		return fn(hc)
+	}}
+}
+
+// hookClient sends the requests of HTTP hooks
+var hookClient = &http.Client{Timeout: 30 * time.Second}
+
+// HTTPHook creates a hook that calls the URL with the site, account and feature as JSON,
+// authenticated with HOOK_API_TOKEN when set. The URL can reference environment variables as
+// ${NAME}; the hook fails when one of them is not set.
+func HTTPHook(name, method, rawURL string) Hook {
+	return Hook{Name: name, Kind: models.HookKindHTTP, Run: func(r *BrowserStackRunner, hc HookContext) error {
+		var missing []string
+		endpoint := os.Expand(rawURL, func(key string) string {
+			value := os.Getenv(key)
+			if value == "" {
+				missing = append(missing, key)
+			}
+			return value
+		})
+		if len(missing) > 0 {
+			return fmt.Errorf("%s is not set", strings.Join(missing, ", "))
+		}
+
+		body, err := json.Marshal(map[string]interface{}{
+			"site":      hc.Site.Name,
+			"email":     hc.Email,
+			"feature":   hc.Feature.Name,
+			"result_id": hc.ResultID,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to encode request: %v", err)
+		}
+
+		req, err := http.NewRequest(method, endpoint, bytes.NewReader(body))
+		if err != nil {
+			return fmt.Errorf("failed to create request: %v", err)
+		}
+		req.Header.Set("Content-Type", "application/json")
+		if token := os.Getenv("HOOK_API_TOKEN"); token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+
+		resp, err := hookClient.Do(req)
+		if err != nil {
+			return fmt.Errorf("%s %s failed: %v", method, endpoint, err)
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+			content, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+			return fmt.Errorf("%s %s returned %s: %s", method, endpoint, resp.Status, strings.TrimSpace(string(content)))
+		}
+
+		return nil
+	}}
+}
+
+// uiHookActions are the browser steps a ui hook can name as its action
+var uiHookActions = map[string]func(r *BrowserStackRunner, selector string) error{
+	"press_escape": func(r *BrowserStackRunner, selector string) error {
+		return r.KeyboardShortcut(selenium.EscapeKey)
+	},
+	"click": func(r *BrowserStackRunner, selector string) error {
+		element, err := r.findContainer(selector)
+		if err != nil {
+			return err
+		}
+		if err := element.Click(); err != nil {
+			return fmt.Errorf("failed to click %s: %v", selector, err)
+		}
+		return nil
+	},
+}
+
+// funcHookActions are the Go functions a func hook can name as its action
+var funcHookActions = map[string]func(hc HookContext) error{
+	"remove_saved_session": func(hc HookContext) error {
+		return hc.DB.Where("site_id = ? AND email = ?", hc.Site.ID, hc.Email).Delete(&models.AuthSession{}).Error
+	},
+}
+
+// ValidateHook checks the phase, kind and action or request of a feature hook
+func ValidateHook(hook models.FeatureHook) error {
+	if hook.Phase != models.HookPhaseBefore && hook.Phase != models.HookPhaseAfter {
+		return fmt.Errorf("invalid hook phase: %s, expected before or after", hook.Phase)
+	}
+	if strings.TrimSpace(hook.Name) == "" {
+		return fmt.Errorf("hook name is required")
+	}
+
+	switch hook.Kind {
+	case models.HookKindUI:
+		if _, ok := uiHookActions[hook.Action]; !ok {
+			return fmt.Errorf("unknown ui hook action: %s", hook.Action)
+		}
+		if hook.Action == "click" && hook.Selector == "" {
+			return fmt.Errorf("ui hook action click requires a selector")
+		}
+	case models.HookKindFunc:
+		if _, ok := funcHookActions[hook.Action]; !ok {
+			return fmt.Errorf("unknown func hook action: %s", hook.Action)
+		}
+	case models.HookKindHTTP:
+		if hook.URL == "" {
+			return fmt.Errorf("http hook url is required")
+		}
+		switch hook.Method {
+		case http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		default:
+			return fmt.Errorf("invalid http hook method: %s", hook.Method)
+		}
+	default:
+		return fmt.Errorf("invalid hook kind: %s, expected ui, http or func", hook.Kind)
+	}
+
+	return nil
+}
+
+// newHook creates the hook a stored feature hook describes
+func newHook(hook models.FeatureHook) (Hook, error) {
+	if err := ValidateHook(hook); err != nil {
+		return Hook{}, err
+	}
+
+	switch hook.Kind {
+	case models.HookKindUI:
+		action, selector := uiHookActions[hook.Action], hook.Selector
+		return UIHook(hook.Name, func(r *BrowserStackRunner, hc HookContext) error {
+			return action(r, selector)
+		}), nil
+	case models.HookKindFunc:
+		return FuncHook(hook.Name, funcHookActions[hook.Action]), nil
+	default:
+		return HTTPHook(hook.Name, hook.Method, hook.URL), nil
+	}
+}
+
+// loadFeatureHooks loads the setup and teardown hooks stored with a feature
+func loadFeatureHooks(db *gorm.DB, featureID uint) (FeatureHooks, error) {
+	var stored []models.FeatureHook
+	if err := db.Where("feature_id = ?", featureID).Order("position ASC, id ASC").Find(&stored).Error; err != nil {
+		return FeatureHooks{}, fmt.Errorf("failed to load hooks: %v", err)
+	}
+
+	var hooks FeatureHooks
+	for _, featureHook := range stored {
+		hook, err := newHook(featureHook)
+		if err != nil {
+			return FeatureHooks{}, fmt.Errorf("invalid hook %q: %v", featureHook.Name, err)
+		}
+		if featureHook.Phase == models.HookPhaseBefore {
+			hooks.Before = append(hooks.Before, hook)
+		} else {
+			hooks.After = append(hooks.After, hook)
+		}
+	}
+
+	return hooks, nil
+}
+
+// runHooks runs the hooks of a phase and records the outcome of each on the result.
+// Every hook runs even when an earlier one fails; the first failure is returned.
+func (r *BrowserStackRunner) runHooks(phase string, hooks []Hook, hc HookContext) error {
+	var firstErr error
+
+	for _, hook := range hooks {
+		startTime := time.Now()
+		err := r.runHook(hook, hc)
+
+		record := models.ResultHook{
+			ResultID: hc.ResultID,
+			Phase:    phase,
+			Name:     hook.Name,
+			Kind:     hook.Kind,
+			Status:   models.HookStatusPassed,
+			Duration: time.Since(startTime).Seconds(),
+		}
+		if err != nil {
+			record.Status = models.HookStatusFailed
+			record.Message = err.Error()
+			if firstErr == nil {
+				firstErr = fmt.Errorf("%s hook %q failed: %v", phase, hook.Name, err)
+			}
+			if hook.Kind == models.HookKindUI {
+				r.TakeStepScreenshot(hc.DB, hc.ResultID, r.browserType, fmt.Sprintf("%s Hook Failed", hook.Name))
+			}
+		}
+
+		if err := hc.DB.Create(&record).Error; err != nil {
+			log.Printf("Warning: Failed to store %s hook %s for Result ID %d: %v", phase, hook.Name, hc.ResultID, err)
+		}
+
+		step := fmt.Sprintf("%s hook %q %s", phase, hook.Name, record.Status)
+		if record.Message != "" {
+			step += ": " + record.Message
+		}
+		if err := r.LogTestStep(step); err != nil {
+			log.Printf("Warning: Failed to log %s hook for %s: %v", phase, r.browserType, err)
+		}
+	}
+
+	return firstErr
+}
+
+// runHook runs a hook, turning a panic of its code into an error
+func (r *BrowserStackRunner) runHook(hook Hook, hc HookContext) (err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = fmt.Errorf("panic: %v", recovered)
+		}
+	}()
+
+	return hook.Run(r, hc)
+}
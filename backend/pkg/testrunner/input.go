@@ -0,0 +1,345 @@
+package testrunner
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/tebeka/selenium"
+)
+
+// errActionsUnsupported is returned when the WebDriver session has no W3C Actions endpoint
+var errActionsUnsupported = errors.New("W3C actions are not supported by this session")
+
+// actionsClient sends the W3C Actions requests, which the selenium client does not implement
+var actionsClient = &http.Client{Timeout: 60 * time.Second}
+
+// performActions sends input source sequences to the W3C Actions endpoint of the session
+func (r *BrowserStackRunner) performActions(sources ...map[string]interface{}) error {
+	return r.actionsRequest(http.MethodPost, map[string]interface{}{"actions": sources})
+}
+
+// releaseActions releases any keys and buttons still held by previous actions
+func (r *BrowserStackRunner) releaseActions() error {
+	return r.actionsRequest(http.MethodDelete, nil)
+}
+
+// actionsRequest calls the session's /actions endpoint
+func (r *BrowserStackRunner) actionsRequest(method string, payload interface{}) error {
+	if r.driver == nil {
+		return fmt.Errorf("driver not initialized")
+	}
+
+	var body io.Reader
+	if payload != nil {
+		data, err := json.Marshal(payload)
+		if err != nil {
+			return fmt.Errorf("failed to encode actions: %v", err)
+		}
+		body = bytes.NewReader(data)
+	}
+
+	endpoint := strings.TrimSuffix(r.config.BaseURL, "/") + "/session/" + r.driver.SessionID() + "/actions"
+	req, err := http.NewRequest(method, endpoint, body)
+	if err != nil {
+		return fmt.Errorf("failed to create actions request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if r.config.Username != "" {
+		req.SetBasicAuth(r.config.Username, r.config.AccessKey)
+	}
+
+	resp, err := actionsClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to perform actions: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusOK {
+		return nil
+	}
+
+	var reply struct {
+		Value struct {
+			Error   string `json:"error"`
+			Message string `json:"message"`
+		} `json:"value"`
+	}
+	content, _ := io.ReadAll(resp.Body)
+	_ = json.Unmarshal(content, &reply)
+
+	if resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusMethodNotAllowed ||
+		reply.Value.Error == "unknown command" || reply.Value.Error == "unknown method" {
+		return errActionsUnsupported
+	}
+
+	return fmt.Errorf("failed to perform actions: status %d: %s %s", resp.StatusCode, reply.Value.Error, reply.Value.Message)
+}
+
+// findContainer finds the element an input action targets, explaining what to check when it is missing
+func (r *BrowserStackRunner) findContainer(selector string) (selenium.WebElement, error) {
+	if r.driver == nil {
+		return nil, fmt.Errorf("driver not initialized")
+	}
+
+	elements, err := r.driver.FindElements(selenium.ByCSSSelector, selector)
+	if err != nil || len(elements) == 0 {
+		currentURL, _ := r.driver.CurrentURL()
+		return nil, fmt.Errorf("container %q not found on %s: the page may not have finished loading or its layout changed, check the selector configured for this site", selector, currentURL)
+	}
+
+	return elements[0], nil
+}
+
+// ScrollToElement scrolls the first element matching the selector to the center of the viewport
+func (r *BrowserStackRunner) ScrollToElement(selector string) error {
+	element, err := r.findContainer(selector)
+	if err != nil {
+		return err
+	}
+
+	if _, err := r.driver.ExecuteScript(`arguments[0].scrollIntoView({ block: 'center', behavior: 'smooth' });`, []interface{}{element}); err != nil {
+		return fmt.Errorf("failed to scroll to %s: %v", selector, err)
+	}
+
+	return nil
+}
+
+// ScrollBy scrolls a container by an offset in pixels, or the window when selector is empty
+func (r *BrowserStackRunner) ScrollBy(selector string, deltaX, deltaY int) error {
+	if r.driver == nil {
+		return fmt.Errorf("driver not initialized")
+	}
+
+	if selector == "" {
+		if _, err := r.driver.ExecuteScript(`window.scrollBy({ left: arguments[0], top: arguments[1], behavior: 'smooth' });`, []interface{}{deltaX, deltaY}); err != nil {
+			return fmt.Errorf("failed to scroll window: %v", err)
+		}
+		return nil
+	}
+
+	container, err := r.findContainer(selector)
+	if err != nil {
+		return err
+	}
+
+	if _, err := r.driver.ExecuteScript(`arguments[0].scrollBy({ left: arguments[1], top: arguments[2], behavior: 'smooth' });`, []interface{}{container, deltaX, deltaY}); err != nil {
+		return fmt.Errorf("failed to scroll %s: %v", selector, err)
+	}
+
+	return nil
+}
+
+// Wheel scrolls the mouse wheel over the center of an element by a delta in pixels
+func (r *BrowserStackRunner) Wheel(selector string, deltaX, deltaY int) error {
+	container, err := r.findContainer(selector)
+	if err != nil {
+		return err
+	}
+
+	err = r.performActions(map[string]interface{}{
+		"type": "wheel",
+		"id":   "wheel",
+		"actions": []map[string]interface{}{
+			{"type": "scroll", "x": 0, "y": 0, "deltaX": deltaX, "deltaY": deltaY, "duration": 200, "origin": container},
+		},
+	})
+	if err != errActionsUnsupported {
+		if err != nil {
+			return fmt.Errorf("failed to scroll wheel over %s: %v", selector, err)
+		}
+		return nil
+	}
+
+	// Fall back to dispatching the wheel event from the page
+	script := `arguments[0].dispatchEvent(new WheelEvent('wheel', { deltaX: arguments[1], deltaY: arguments[2], deltaMode: 0, bubbles: true }));`
+	if _, err := r.driver.ExecuteScript(script, []interface{}{container, deltaX, deltaY}); err != nil {
+		return fmt.Errorf("failed to dispatch wheel event on %s: %v", selector, err)
+	}
+
+	return nil
+}
+
+// Swipe drags a finger from the center of an element by an offset, as on a touch screen
+func (r *BrowserStackRunner) Swipe(selector string, deltaX, deltaY int, duration time.Duration) error {
+	container, err := r.findContainer(selector)
+	if err != nil {
+		return err
+	}
+
+	err = r.performActions(map[string]interface{}{
+		"type":       "pointer",
+		"id":         "finger",
+		"parameters": map[string]string{"pointerType": "touch"},
+		"actions": []map[string]interface{}{
+			{"type": "pointerMove", "duration": 0, "origin": container, "x": 0, "y": 0},
+			{"type": "pointerDown", "button": 0},
+			{"type": "pointerMove", "duration": int(duration / time.Millisecond), "origin": "pointer", "x": deltaX, "y": deltaY},
+			{"type": "pointerUp", "button": 0},
+		},
+	})
+	if err != errActionsUnsupported {
+		if err != nil {
+			return fmt.Errorf("failed to swipe on %s: %v", selector, err)
+		}
+		return r.releaseActions()
+	}
+
+	// Fall back to dispatching touch events from the page where the browser supports them
+	script := `
+		var el = arguments[0];
+		if (typeof Touch === 'undefined') {
+			return 'touch events are not supported by this browser';
+		}
+		var rect = el.getBoundingClientRect();
+		var x = rect.left + rect.width / 2;
+		var y = rect.top + rect.height / 2;
+		var touch = function(dx, dy) {
+			return new Touch({ identifier: 1, target: el, clientX: x + dx, clientY: y + dy });
+		};
+		el.dispatchEvent(new TouchEvent('touchstart', { touches: [touch(0, 0)], changedTouches: [touch(0, 0)], bubbles: true }));
+		el.dispatchEvent(new TouchEvent('touchmove', { touches: [touch(arguments[1], arguments[2])], changedTouches: [touch(arguments[1], arguments[2])], bubbles: true }));
+		el.dispatchEvent(new TouchEvent('touchend', { touches: [], changedTouches: [touch(arguments[1], arguments[2])], bubbles: true }));
+		return '';
+	`
+	result, err := r.driver.ExecuteScript(script, []interface{}{container, deltaX, deltaY})
+	if err != nil {
+		return fmt.Errorf("failed to dispatch touch events on %s: %v", selector, err)
+	}
+	if message, _ := result.(string); message != "" {
+		return fmt.Errorf("failed to swipe on %s: %s", selector, message)
+	}
+
+	return nil
+}
+
+// Hover moves the mouse over the center of an element
+func (r *BrowserStackRunner) Hover(selector string) error {
+	element, err := r.findContainer(selector)
+	if err != nil {
+		return err
+	}
+
+	err = r.performActions(map[string]interface{}{
+		"type":       "pointer",
+		"id":         "mouse",
+		"parameters": map[string]string{"pointerType": "mouse"},
+		"actions": []map[string]interface{}{
+			{"type": "pointerMove", "duration": 100, "origin": element, "x": 0, "y": 0},
+		},
+	})
+	if err != errActionsUnsupported {
+		if err != nil {
+			return fmt.Errorf("failed to hover %s: %v", selector, err)
+		}
+		return nil
+	}
+
+	// Fall back to the legacy mouse move command
+	if err := element.MoveTo(0, 0); err != nil {
+		return fmt.Errorf("failed to hover %s: %v", selector, err)
+	}
+
+	return nil
+}
+
+// Drag presses the mouse on the source element, moves it onto the target element and releases it
+func (r *BrowserStackRunner) Drag(sourceSelector, targetSelector string) error {
+	source, err := r.findContainer(sourceSelector)
+	if err != nil {
+		return err
+	}
+	target, err := r.findContainer(targetSelector)
+	if err != nil {
+		return err
+	}
+
+	err = r.performActions(map[string]interface{}{
+		"type":       "pointer",
+		"id":         "mouse",
+		"parameters": map[string]string{"pointerType": "mouse"},
+		"actions": []map[string]interface{}{
+			{"type": "pointerMove", "duration": 0, "origin": source, "x": 0, "y": 0},
+			{"type": "pointerDown", "button": 0},
+			{"type": "pause", "duration": 100},
+			{"type": "pointerMove", "duration": 500, "origin": target, "x": 0, "y": 0},
+			{"type": "pointerUp", "button": 0},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to drag %s to %s: %v", sourceSelector, targetSelector, err)
+	}
+
+	return r.releaseActions()
+}
+
+// KeyboardShortcut presses the keys together and releases them in reverse order,
+// e.g. KeyboardShortcut(selenium.ControlKey, "a")
+func (r *BrowserStackRunner) KeyboardShortcut(keys ...string) error {
+	if r.driver == nil {
+		return fmt.Errorf("driver not initialized")
+	}
+
+	var actions []map[string]interface{}
+	for _, key := range keys {
+		actions = append(actions, map[string]interface{}{"type": "keyDown", "value": key})
+	}
+	for i := len(keys) - 1; i >= 0; i-- {
+		actions = append(actions, map[string]interface{}{"type": "keyUp", "value": keys[i]})
+	}
+
+	err := r.performActions(map[string]interface{}{
+		"type":    "key",
+		"id":      "keyboard",
+		"actions": actions,
+	})
+	if err != errActionsUnsupported {
+		if err != nil {
+			return fmt.Errorf("failed to press %s: %v", describeKeys(keys), err)
+		}
+		return nil
+	}
+
+	// Fall back to the legacy key commands
+	for _, key := range keys {
+		if err := r.driver.KeyDown(key); err != nil {
+			return fmt.Errorf("failed to press %s: %v", describeKeys(keys), err)
+		}
+	}
+	for i := len(keys) - 1; i >= 0; i-- {
+		if err := r.driver.KeyUp(keys[i]); err != nil {
+			return fmt.Errorf("failed to release %s: %v", describeKeys(keys), err)
+		}
+	}
+
+	return nil
+}
+
+// describeKeys formats a key combination for error messages
+func describeKeys(keys []string) string {
+	names := map[string]string{
+		selenium.ControlKey: "Ctrl",
+		selenium.ShiftKey:   "Shift",
+		selenium.AltKey:     "Alt",
+		selenium.MetaKey:    "Meta",
+		selenium.EnterKey:   "Enter",
+		selenium.EscapeKey:  "Escape",
+		selenium.TabKey:     "Tab",
+	}
+
+	parts := make([]string, 0, len(keys))
+	for _, key := range keys {
+		if name, ok := names[key]; ok {
+			parts = append(parts, name)
+		} else {
+			parts = append(parts, key)
+		}
+	}
+
+	return strings.Join(parts, "+")
+}
@@ -0,0 +1,370 @@
+package testrunner
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+	"qa-automation-system/backend/models"
+)
+
+// collectLinksScript returns the absolute URLs of every link, image and script on the page
+const collectLinksScript = `
+	var links = [];
+	document.querySelectorAll('a[href]').forEach(function(el) { links.push({ tag: 'a', url: el.href }); });
+	document.querySelectorAll('img[src]').forEach(function(el) { links.push({ tag: 'img', url: el.src }); });
+	document.querySelectorAll('script[src]').forEach(function(el) { links.push({ tag: 'script', url: el.src }); });
+	return links;
+`
+
+// LinkBrowser is the part of a WebDriver session the link checker needs to crawl pages
+type LinkBrowser interface {
+	Get(url string) error
+	ExecuteScript(script string, args []interface{}) (interface{}, error)
+}
+
+// PageLink is a URL referenced by a page element
+type PageLink struct {
+	Tag string
+	URL string
+}
+
+// BrokenLink is a URL that failed its HTTP check
+type BrokenLink struct {
+	Page       string
+	Tag        string
+	URL        string
+	StatusCode int
+	Error      string
+	Internal   bool
+}
+
+// defaultLinkCheckSkip are the URL parts of the side-effecting links skipped by default
+var defaultLinkCheckSkip = []string{"logout", "log-out", "signout", "sign-out"}
+
+// LinkChecker crawls the internal pages of a site through a browser and checks
+// the HTTP status of every link, image and script they reference
+type LinkChecker struct {
+	Browser LinkBrowser
+	Client  *http.Client
+	// MaxDepth is the number of links followed from the start page
+	MaxDepth int
+	// MaxPages caps the pages visited, 0 means unlimited
+	MaxPages int
+	// Skip lists the URL parts, matched case-insensitively, of the links neither followed nor
+	// checked because visiting them has side effects, such as logging out the session
+	Skip        []string
+	PageWait    time.Duration
+	Concurrency int
+
+	mu       sync.Mutex
+	statuses map[string]linkStatus
+}
+
+// linkStatus is the cached outcome of an HTTP check
+type linkStatus struct {
+	code int
+	err  string
+}
+
+// NewLinkChecker creates a link checker with the depth and page limits from
+// LINK_CHECK_DEPTH (default 1) and LINK_CHECK_MAX_PAGES (default 20, 0 for unlimited),
+// skipping the links matching LINK_CHECK_SKIP (comma-separated, default the logout links)
+func NewLinkChecker(browser LinkBrowser) *LinkChecker {
+	skip := defaultLinkCheckSkip
+	if value := os.Getenv("LINK_CHECK_SKIP"); value != "" {
+		skip = nil
+		for _, part := range strings.Split(value, ",") {
+			if part = strings.TrimSpace(part); part != "" {
+				skip = append(skip, part)
+			}
+		}
+	}
+
+	return &LinkChecker{
+		Browser:     browser,
+		Client:      &http.Client{Timeout: 15 * time.Second},
+		MaxDepth:    envInt("LINK_CHECK_DEPTH", 1),
+		MaxPages:    envInt("LINK_CHECK_MAX_PAGES", 20),
+		Skip:        skip,
+		PageWait:    3 * time.Second,
+		Concurrency: 5,
+	}
+}
+
+// skipped reports whether a link matches the skip list
+func (lc *LinkChecker) skipped(rawURL string) bool {
+	lower := strings.ToLower(rawURL)
+	for _, part := range lc.Skip {
+		if strings.Contains(lower, strings.ToLower(part)) {
+			return true
+		}
+	}
+	return false
+}
+
+// envInt reads a non-negative integer environment variable
+func envInt(name string, fallback int) int {
+	value, err := strconv.Atoi(os.Getenv(name))
+	if err != nil || value < 0 {
+		return fallback
+	}
+	return value
+}
+
+// Crawl visits startURL and the internal pages it links to up to MaxDepth and MaxPages, and
+// returns the broken URLs and the number of pages visited. Links on the skip list are ignored.
+func (lc *LinkChecker) Crawl(startURL string) ([]BrokenLink, int, error) {
+	start, err := url.Parse(startURL)
+	if err != nil {
+		return nil, 0, fmt.Errorf("invalid start URL %s: %v", startURL, err)
+	}
+
+	type queued struct {
+		url   string
+		depth int
+	}
+
+	queue := []queued{{url: normalizeLink(start), depth: 0}}
+	visited := map[string]bool{queue[0].url: true}
+	var broken []BrokenLink
+	pages := 0
+
+	for len(queue) > 0 && (lc.MaxPages == 0 || pages < lc.MaxPages) {
+		page := queue[0]
+		queue = queue[1:]
+
+		links, err := lc.pageLinks(page.url)
+		if err != nil {
+			return broken, pages, err
+		}
+		pages++
+
+		var toCheck []PageLink
+		for _, link := range links {
+			parsed, err := url.Parse(link.URL)
+			if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") {
+				continue
+			}
+			link.URL = normalizeLink(parsed)
+			if lc.skipped(link.URL) {
+				continue
+			}
+			toCheck = append(toCheck, link)
+
+			// Follow internal page links to the next depth
+			if link.Tag == "a" && parsed.Host == start.Host && page.depth < lc.MaxDepth && !visited[link.URL] {
+				visited[link.URL] = true
+				queue = append(queue, queued{url: link.URL, depth: page.depth + 1})
+			}
+		}
+
+		for _, link := range lc.checkLinks(toCheck) {
+			parsed, _ := url.Parse(link.URL)
+			link.Page = page.url
+			link.Internal = parsed.Host == start.Host
+			broken = append(broken, link)
+		}
+	}
+
+	return broken, pages, nil
+}
+
+// pageLinks opens a page in the browser and collects the URLs it references
+func (lc *LinkChecker) pageLinks(pageURL string) ([]PageLink, error) {
+	if err := lc.Browser.Get(pageURL); err != nil {
+		return nil, fmt.Errorf("failed to navigate to %s: %v", pageURL, err)
+	}
+
+	// Wait for the page to render its links
+	time.Sleep(lc.PageWait)
+
+	value, err := lc.Browser.ExecuteScript(collectLinksScript, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect links on %s: %v", pageURL, err)
+	}
+
+	items, _ := value.([]interface{})
+	links := make([]PageLink, 0, len(items))
+	seen := make(map[PageLink]bool)
+	for _, item := range items {
+		fields, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		tag, _ := fields["tag"].(string)
+		href, _ := fields["url"].(string)
+		link := PageLink{Tag: tag, URL: href}
+		if href == "" || seen[link] {
+			continue
+		}
+		seen[link] = true
+		links = append(links, link)
+	}
+
+	return links, nil
+}
+
+// checkLinks checks the links concurrently and returns the broken ones
+func (lc *LinkChecker) checkLinks(links []PageLink) []BrokenLink {
+	concurrency := lc.Concurrency
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+
+	var (
+		wg     sync.WaitGroup
+		mu     sync.Mutex
+		broken []BrokenLink
+	)
+	semaphore := make(chan struct{}, concurrency)
+
+	for _, link := range links {
+		wg.Add(1)
+		semaphore <- struct{}{}
+		go func(link PageLink) {
+			defer wg.Done()
+			defer func() { <-semaphore }()
+
+			status := lc.check(link.URL)
+			if status.err == "" && status.code < 400 {
+				return
+			}
+
+			mu.Lock()
+			broken = append(broken, BrokenLink{Tag: link.Tag, URL: link.URL, StatusCode: status.code, Error: status.err})
+			mu.Unlock()
+		}(link)
+	}
+	wg.Wait()
+
+	return broken
+}
+
+// check returns the HTTP status of a URL, trying HEAD first and falling back to GET
+// for servers that reject HEAD requests. Results are cached for the whole crawl.
+func (lc *LinkChecker) check(rawURL string) linkStatus {
+	lc.mu.Lock()
+	if lc.statuses == nil {
+		lc.statuses = make(map[string]linkStatus)
+	}
+	if status, ok := lc.statuses[rawURL]; ok {
+		lc.mu.Unlock()
+		return status
+	}
+	lc.mu.Unlock()
+
+	status := lc.request(http.MethodHead, rawURL)
+	if status.err != "" || status.code >= 400 {
+		status = lc.request(http.MethodGet, rawURL)
+	}
+
+	lc.mu.Lock()
+	lc.statuses[rawURL] = status
+	lc.mu.Unlock()
+
+	return status
+}
+
+// request performs a single HTTP check
+func (lc *LinkChecker) request(method, rawURL string) linkStatus {
+	req, err := http.NewRequest(method, rawURL, nil)
+	if err != nil {
+		return linkStatus{err: err.Error()}
+	}
+	req.Header.Set("User-Agent", "QA Automation System Link Check")
+
+	resp, err := lc.Client.Do(req)
+	if err != nil {
+		return linkStatus{err: err.Error()}
+	}
+	resp.Body.Close()
+
+	return linkStatus{code: resp.StatusCode}
+}
+
+// normalizeLink drops the fragment so anchors on the same page are checked once
+func normalizeLink(u *url.URL) string {
+	normalized := *u
+	normalized.Fragment = ""
+	normalized.RawFragment = ""
+	return normalized.String()
+}
+
+// Link Check
+func (r *BrowserStackRunner) LinkCheck(siteName string, featureName string, browserType string, resultID uint, db *gorm.DB) error {
+	if r.driver == nil {
+		return fmt.Errorf("driver not initialized")
+	}
+
+	checker := NewLinkChecker(r.driver)
+
+	// Share the logged-in session so internal pages don't redirect to login
+	cookies, err := r.driver.GetCookies()
+	if err != nil {
+		log.Printf("Warning: Failed to read session cookies for %s: %v", browserType, err)
+	} else if jar, err := cookiejar.New(nil); err == nil {
+		siteURL := &url.URL{Scheme: "https", Host: siteName, Path: "/"}
+		var httpCookies []*http.Cookie
+		for _, cookie := range cookies {
+			httpCookies = append(httpCookies, &http.Cookie{Name: cookie.Name, Value: cookie.Value})
+		}
+		jar.SetCookies(siteURL, httpCookies)
+		checker.Client.Jar = jar
+	}
+
+	broken, pages, err := checker.Crawl("https://" + siteName)
+	if err != nil {
+		return err
+	}
+
+	failing := 0
+	for _, link := range broken {
+		message := link.Error
+		if message == "" {
+			message = fmt.Sprintf("HTTP %d %s", link.StatusCode, http.StatusText(link.StatusCode))
+		}
+
+		finding := models.ResultFinding{
+			ResultID:   resultID,
+			Type:       models.FindingTypeBrokenLink,
+			Page:       link.Page,
+			Rule:       link.Tag,
+			URL:        link.URL,
+			StatusCode: link.StatusCode,
+			Message:    message,
+		}
+		if err := db.Create(&finding).Error; err != nil {
+			log.Printf("Warning: Failed to store broken link finding for %s: %v", browserType, err)
+		}
+
+		// External sites often block automated requests, so only their page links are lenient
+		if link.Internal || link.Tag != "a" {
+			failing++
+		}
+	}
+
+	if err := r.LogTestStep(fmt.Sprintf("%d broken URLs found across %d pages", len(broken), pages)); err != nil {
+		log.Printf("Warning: Failed to log link check for %s: %v", browserType, err)
+	}
+
+	// Take screenshot of the last crawled page
+	r.TakeStepScreenshot(db, resultID, browserType, featureName)
+
+	if failing > 0 {
+		return fmt.Errorf("%d broken internal links or assets found across %d pages", failing, pages)
+	}
+	if len(broken) > 0 {
+		r.addWarning(fmt.Sprintf("%d broken external links found across %d pages", len(broken), pages))
+	}
+
+	return nil
+}
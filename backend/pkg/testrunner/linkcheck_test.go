@@ -0,0 +1,151 @@
+package testrunner
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"sort"
+	"sync"
+	"testing"
+)
+
+// fakeBrowser loads pages over HTTP and collects their links like collectLinksScript
+type fakeBrowser struct {
+	client  *http.Client
+	current string
+}
+
+var fakeLinkPattern = regexp.MustCompile(`<(a|img|script) (?:href|src)="([^"]*)"`)
+
+func (b *fakeBrowser) Get(pageURL string) error {
+	b.current = pageURL
+	return nil
+}
+
+func (b *fakeBrowser) ExecuteScript(script string, args []interface{}) (interface{}, error) {
+	resp, err := b.client.Get(b.current)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	base, _ := url.Parse(b.current)
+	var links []interface{}
+	for _, match := range fakeLinkPattern.FindAllStringSubmatch(string(body), -1) {
+		ref, err := url.Parse(match[2])
+		if err != nil {
+			continue
+		}
+		links = append(links, map[string]interface{}{"tag": match[1], "url": base.ResolveReference(ref).String()})
+	}
+	return links, nil
+}
+
+// newTestSite serves a small site and records the paths requested
+func newTestSite(t *testing.T) (*httptest.Server, func(string) int) {
+	pages := map[string]string{
+		"/":       `<a href="/about">About</a> <a href="/logout">Log out</a> <img src="/missing.png"> <a href="#top">Top</a>`,
+		"/about":  `<a href="/team">Team</a> <script src="/app.js"></script> <a href="/gone">Gone</a>`,
+		"/team":   `<a href="/deeper">Deeper</a>`,
+		"/deeper": `deep`,
+		"/app.js": `console.log("ok")`,
+		"/logout": `logged out`,
+	}
+
+	var mu sync.Mutex
+	hits := make(map[string]int)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		hits[r.URL.Path]++
+		mu.Unlock()
+
+		body, ok := pages[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprint(w, body)
+	}))
+	t.Cleanup(server.Close)
+
+	return server, func(path string) int {
+		mu.Lock()
+		defer mu.Unlock()
+		return hits[path]
+	}
+}
+
+func newTestChecker(server *httptest.Server) *LinkChecker {
+	return &LinkChecker{
+		Browser:     &fakeBrowser{client: server.Client()},
+		Client:      server.Client(),
+		MaxDepth:    1,
+		Skip:        defaultLinkCheckSkip,
+		Concurrency: 2,
+	}
+}
+
+func TestLinkCheckerCrawl(t *testing.T) {
+	server, hits := newTestSite(t)
+	checker := newTestChecker(server)
+
+	broken, pages, err := checker.Crawl(server.URL)
+	if err != nil {
+		t.Fatalf("Crawl returned error: %v", err)
+	}
+
+	// The start page and /about, /team is beyond MaxDepth
+	if pages != 2 {
+		t.Errorf("pages = %d, want 2", pages)
+	}
+
+	var got []string
+	for _, link := range broken {
+		got = append(got, fmt.Sprintf("%s %s %d %v", link.Tag, link.URL[len(server.URL):], link.StatusCode, link.Internal))
+	}
+	sort.Strings(got)
+	want := []string{"a /gone 404 true", "img /missing.png 404 true"}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("broken = %v, want %v", got, want)
+	}
+
+	if hits("/logout") != 0 {
+		t.Errorf("logout link was requested %d times, want skipped", hits("/logout"))
+	}
+	if hits("/deeper") != 0 {
+		t.Errorf("page beyond MaxDepth was requested")
+	}
+}
+
+func TestLinkCheckerMaxPages(t *testing.T) {
+	server, _ := newTestSite(t)
+	checker := newTestChecker(server)
+	checker.MaxDepth = 5
+
+	_, pages, err := checker.Crawl(server.URL)
+	if err != nil {
+		t.Fatalf("Crawl returned error: %v", err)
+	}
+	// Every internal page, including the broken /gone, but not /logout
+	if pages != 5 {
+		t.Errorf("unlimited pages = %d, want 5", pages)
+	}
+
+	checker = newTestChecker(server)
+	checker.MaxDepth = 5
+	checker.MaxPages = 2
+	_, pages, err = checker.Crawl(server.URL)
+	if err != nil {
+		t.Fatalf("Crawl returned error: %v", err)
+	}
+	if pages != 2 {
+		t.Errorf("pages = %d, want MaxPages 2", pages)
+	}
+}
@@ -0,0 +1,136 @@
+package testrunner
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+	"qa-automation-system/backend/models"
+)
+
+// pageMetricsScript reads the Navigation Timing and paint entries of the current page
+// and observes the buffered LCP and layout shift entries before returning
+const pageMetricsScript = `
+	var done = arguments[arguments.length - 1];
+	var metrics = { url: window.location.href };
+
+	var nav = performance.getEntriesByType('navigation')[0];
+	if (nav) {
+		metrics.ttfb = nav.responseStart;
+		metrics.dom_content_loaded = nav.domContentLoadedEventEnd;
+		metrics.load_event_end = nav.loadEventEnd;
+	} else if (performance.timing) {
+		var t = performance.timing;
+		metrics.ttfb = t.responseStart - t.navigationStart;
+		metrics.dom_content_loaded = t.domContentLoadedEventEnd - t.navigationStart;
+		metrics.load_event_end = t.loadEventEnd - t.navigationStart;
+	}
+
+	performance.getEntriesByType('paint').forEach(function(entry) {
+		if (entry.name === 'first-paint') {
+			metrics.first_paint = entry.startTime;
+		}
+		if (entry.name === 'first-contentful-paint') {
+			metrics.first_contentful_paint = entry.startTime;
+		}
+	});
+
+	try {
+		new PerformanceObserver(function(list) {
+			list.getEntries().forEach(function(entry) {
+				var time = entry.renderTime || entry.loadTime || entry.startTime;
+				metrics.largest_contentful_paint = Math.max(metrics.largest_contentful_paint || 0, time);
+			});
+		}).observe({ type: 'largest-contentful-paint', buffered: true });
+	} catch (e) {}
+
+	try {
+		new PerformanceObserver(function(list) {
+			list.getEntries().forEach(function(entry) {
+				if (!entry.hadRecentInput) {
+					metrics.cumulative_layout_shift = (metrics.cumulative_layout_shift || 0) + entry.value;
+				}
+			});
+		}).observe({ type: 'layout-shift', buffered: true });
+		metrics.cumulative_layout_shift = metrics.cumulative_layout_shift || 0;
+	} catch (e) {}
+
+	// Buffered observer callbacks are delivered asynchronously
+	setTimeout(function() { done(metrics); }, 100);
+`
+
+// CollectPageMetrics reads the performance timings of the current page and queues them for the given step
+func (r *BrowserStackRunner) CollectPageMetrics(page string) (*models.PageMetric, error) {
+	if r.driver == nil {
+		return nil, fmt.Errorf("driver not initialized")
+	}
+
+	if err := r.driver.SetAsyncScriptTimeout(5 * time.Second); err != nil {
+		return nil, fmt.Errorf("failed to set script timeout: %v", err)
+	}
+
+	response, err := r.driver.ExecuteScriptAsyncRaw(pageMetricsScript, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect page metrics: %v", err)
+	}
+
+	var reply struct {
+		Value models.PageMetric `json:"value"`
+	}
+	if err := json.Unmarshal(response, &reply); err != nil {
+		return nil, fmt.Errorf("failed to parse page metrics: %v", err)
+	}
+
+	metric := reply.Value
+	metric.Page = page
+	// A longer URL would fail the insert of every metric of the result
+	if len(metric.URL) > models.MaxPageMetricURLLength {
+		metric.URL = metric.URL[:models.MaxPageMetricURLLength]
+	}
+	r.pageMetrics = append(r.pageMetrics, metric)
+
+	return &metric, nil
+}
+
+// collectPageMetrics collects the page timings of a navigation, logging instead of failing the test
+func (r *BrowserStackRunner) collectPageMetrics(page string) {
+	metric, err := r.CollectPageMetrics(page)
+	if err != nil {
+		log.Printf("Warning: Failed to collect %s metrics for %s: %v", page, r.browserType, err)
+		return
+	}
+	log.Printf("%s metrics for %s: TTFB %.0fms, load %.0fms", page, r.browserType, metric.TimeToFirstByte, metric.LoadEventEnd)
+}
+
+// recordMetric queues a named measurement taken during the test
+func (r *BrowserStackRunner) recordMetric(name string, value float64, unit string) {
+	r.metrics = append(r.metrics, models.ResultMetric{Name: name, Value: value, Unit: unit})
+	if err := r.LogTestStep(fmt.Sprintf("%s: %.0f%s", name, value, unit)); err != nil {
+		log.Printf("Warning: Failed to log %s for %s: %v", name, r.browserType, err)
+	}
+}
+
+// storeMetrics saves the queued page timings and measurements for a result
+func (r *BrowserStackRunner) storeMetrics(db *gorm.DB, resultID uint) {
+	if len(r.pageMetrics) > 0 {
+		for i := range r.pageMetrics {
+			r.pageMetrics[i].ResultID = resultID
+		}
+		if err := db.Create(&r.pageMetrics).Error; err != nil {
+			log.Printf("Warning: Failed to store page metrics for Result ID %d: %v", resultID, err)
+		}
+		r.pageMetrics = nil
+	}
+
+	if len(r.metrics) > 0 {
+		for i := range r.metrics {
+			r.metrics[i].ResultID = resultID
+		}
+		if err := db.Create(&r.metrics).Error; err != nil {
+			log.Printf("Warning: Failed to store metrics for Result ID %d: %v", resultID, err)
+		}
+		r.metrics = nil
+	}
+}
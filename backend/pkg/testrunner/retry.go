@@ -0,0 +1,107 @@
+package testrunner
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+	"qa-automation-system/backend/models"
+)
+
+// nextAttempt decides on the outcome of an attempt. A failure the policy retries gets a new result
+// linked to the failed one, returned after the backoff; a pass after failed attempts is noted on
+// the result. The browser session is replaced when the failure came from the infrastructure.
+func (r *BrowserStackRunner) nextAttempt(db *gorm.DB, run models.Run, result models.Result, attempt int, policy models.RetryPolicy, state *sessionState) (models.Result, bool) {
+	var outcome models.Result
+	if err := db.Select("id", "retry_of_id", "status", "error_log", "failure_category").First(&outcome, result.ID).Error; err != nil {
+		log.Printf("Warning: Failed to read outcome of Result ID %d: %v", result.ID, err)
+		return result, false
+	}
+
+	if outcome.Status != "failed" {
+		if attempt > 1 {
+			r.markPassedOnRetry(db, outcome, attempt)
+		}
+		return result, false
+	}
+
+	category := outcome.FailureCategory
+	if category == "" {
+		category = ClassifyFailure(outcome.ErrorLog)
+	}
+	if !policy.ShouldRetry(attempt, category) {
+		return result, false
+	}
+
+	delay := policy.Delay(attempt)
+	if err := r.LogTestStep(fmt.Sprintf("Attempt %d of %d failed with a %s failure, retrying in %v",
+		attempt, policy.MaxAttempts, category, delay)); err != nil {
+		log.Printf("Warning: Failed to log retry for Result ID %d: %v", result.ID, err)
+	}
+	time.Sleep(delay)
+
+	// A broken browser session is replaced by a new one for the retry
+	if category == models.FailureInfrastructure && state.started {
+		r.closeSession()
+		*state = sessionState{}
+	}
+
+	retry := models.Result{
+		RunID:     result.RunID,
+		RetryOfID: &result.ID,
+		Attempt:   attempt + 1,
+		SiteID:    run.SiteID,
+		DeviceID:  run.DeviceID,
+		FeatureID: result.FeatureID,
+		Browser:   result.Browser,
+		Status:    "processing",
+	}
+	if err := db.Omit("Site", "Device", "Feature").Create(&retry).Error; err != nil {
+		log.Printf("Warning: Failed to create retry of Result ID %d: %v", result.ID, err)
+		return result, false
+	}
+
+	return retry, true
+}
+
+// markPassedOnRetry notes on a passing result that its earlier attempts failed and closes the
+// triage of those attempts
+func (r *BrowserStackRunner) markPassedOnRetry(db *gorm.DB, outcome models.Result, attempt int) {
+	note := fmt.Sprintf("Passed on retry, attempt %d", attempt)
+	errorLog := note
+	if outcome.ErrorLog != "" {
+		errorLog += "\n" + outcome.ErrorLog
+	}
+
+	if err := db.Model(&models.Result{}).Where("id = ?", outcome.ID).Updates(map[string]interface{}{
+		"passed_on_retry": true,
+		"error_log":       errorLog,
+	}).Error; err != nil {
+		log.Printf("Warning: Failed to mark Result ID %d as passed on retry: %v", outcome.ID, err)
+	}
+
+	var failedIDs []uint
+	for previous := outcome.RetryOfID; previous != nil; {
+		failedIDs = append(failedIDs, *previous)
+		var earlier models.Result
+		if err := db.Select("id", "retry_of_id").First(&earlier, *previous).Error; err != nil {
+			log.Printf("Warning: Failed to read earlier attempt Result ID %d: %v", *previous, err)
+			break
+		}
+		previous = earlier.RetryOfID
+	}
+	closeRetriedTriage(db, failedIDs)
+
+	if err := r.LogTestStep(note); err != nil {
+		log.Printf("Warning: Failed to log pass on retry for Result ID %d: %v", outcome.ID, err)
+	}
+}
+
+// closeSession ends the browser session, so the next attempt opens a new one
+func (r *BrowserStackRunner) closeSession() {
+	if err := r.Close(); err != nil {
+		log.Printf("Warning: Failed to close %s session: %v", r.browserType, err)
+	}
+	r.driver = nil
+}
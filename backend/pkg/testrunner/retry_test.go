@@ -0,0 +1,33 @@
+package testrunner
+
+import (
+	"testing"
+
+	"qa-automation-system/backend/models"
+)
+
+func TestDefaultPolicyRetries(t *testing.T) {
+	policy := models.RetryPolicy{MaxAttempts: 3}
+
+	tests := []struct {
+		errorLog string
+		want     bool
+	}{
+		{"assertion failed: expected .btn-chat-profile to be visible within 10s", false},
+		{"assertion failed: expected 0 elements matching .login-text within 0s", false},
+		{"failed to find .login-text button: no such element", false},
+		{"failed to initialize WebDriver: unexpected EOF", true},
+		{"timeout after 20s: condition not met", true},
+	}
+
+	for _, tt := range tests {
+		category := ClassifyFailure(tt.errorLog)
+		if got := policy.ShouldRetry(1, category); got != tt.want {
+			t.Errorf("ShouldRetry(1, %s) for %q = %v, want %v", category, tt.errorLog, got, tt.want)
+		}
+	}
+
+	if policy.ShouldRetry(3, models.FailureInfrastructure) {
+		t.Errorf("ShouldRetry on the last attempt = true, want false")
+	}
+}
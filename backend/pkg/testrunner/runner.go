@@ -7,10 +7,12 @@ import (
 	"net/http"
 	"os"
 	"path/filepath"
+	"regexp"
 	"strings"
 	"time"
 
 	"github.com/tebeka/selenium"
+	slog "github.com/tebeka/selenium/log"
 	"gorm.io/gorm"
 	"qa-automation-system/backend/config"
 	"qa-automation-system/backend/models"
@@ -18,9 +20,15 @@ import (
 
 // BrowserStackRunner handles browser automation using BrowserStack
 type BrowserStackRunner struct {
-	driver selenium.WebDriver
-	config *BrowserStackConfig
-	db     *gorm.DB
+	driver      selenium.WebDriver
+	config      *BrowserStackConfig
+	db          *gorm.DB
+	browserType string
+	pageMetrics []models.PageMetric
+	metrics     []models.ResultMetric
+	warnings    []string
+
+	assertionFailures []AssertionFailure
 }
 
 // BrowserStackConfig holds BrowserStack configuration
@@ -31,6 +39,7 @@ type BrowserStackConfig struct {
 	BaseURL     string
 	ProjectName string
 	BuildName   string
+	CaptureHAR  bool
 }
 
 // TestResult represents a test execution result
@@ -45,6 +54,21 @@ type TestResult struct {
 	Timestamp time.Time
 }
 
+// RunOptions holds optional settings for a test run
+type RunOptions struct {
+	CaptureHAR bool
+	// Browsers replaces the default browsers of the environment
+	Browsers []string
+	// RerunOf maps the RerunKey of each feature and browser a rerun repeats to the original
+	// result. When set, a browser only tests the features it has an entry for.
+	RerunOf map[string]uint
+}
+
+// RerunKey identifies a feature on a browser in RunOptions.RerunOf
+func RerunKey(featureID uint, browser string) string {
+	return fmt.Sprintf("%d/%s", featureID, browser)
+}
+
 // NewBrowserStackRunner creates a new BrowserStack runner instance
 func NewBrowserStackRunner() *BrowserStackRunner {
 	return &BrowserStackRunner{
@@ -110,12 +134,22 @@ func (r *BrowserStackRunner) Initialize(browserType string) error {
 			caps[k] = v
 		}
 
+		// Enable the DevTools performance log to record network traffic
+		if r.config.CaptureHAR {
+			if key := harLoggingPrefsKey(browserType); key != "" {
+				caps[key] = slog.Capabilities{slog.Performance: slog.All}
+			} else {
+				log.Printf("Warning: HAR capture is not supported on %s", browserType)
+			}
+		}
+
 		// Initialize WebDriver
 		driver, err := selenium.NewRemote(caps, r.config.BaseURL)
 		if err != nil {
 			return fmt.Errorf("failed to initialize WebDriver: %v", err)
 		}
 		r.driver = driver
+		r.browserType = browserType
 
 		// Maximize browser window
 		if err := driver.MaximizeWindow(""); err != nil {
@@ -221,8 +255,10 @@ func (r *BrowserStackRunner) StoreTestResult(result *TestResult) error {
 	return nil
 }
 
-// RunTestInBackground runs the test in the background for multiple browsers
-func RunTestInBackground(siteID, deviceID, featureID uint, email, password string) {
+// RunTestInBackground runs the features of a run in the background for multiple browsers.
+// Every feature gets its own browser session, unless the run is a single session run which
+// logs in once per browser and tests the features one after another.
+func RunTestInBackground(runID uint, featureIDs []uint, email, password string, options RunOptions) {
 	appEnv := os.Getenv("APP_ENV")
 	
 	// Define browsers to test
@@ -231,6 +267,9 @@ func RunTestInBackground(siteID, deviceID, featureID uint, email, password strin
 	if appEnv != "production" {
 		browsers = []string{"chrome"}
 	}
+	if len(options.Browsers) > 0 {
+		browsers = options.Browsers
+	}
 
 	// Initialize database connection
 	db, err := config.InitDB()
@@ -239,20 +278,33 @@ func RunTestInBackground(siteID, deviceID, featureID uint, email, password strin
 		return
 	}
 
-	var site models.Site
-	if err := db.First(&site, siteID).Error; err != nil {
-		log.Printf("Site not found")
+	var run models.Run
+	if err := db.Preload("Site").Preload("Device").First(&run, runID).Error; err != nil {
+		log.Printf("Run not found")
 		return
 	}
 
-	var device models.Device
-	if err := db.First(&device, deviceID).Error; err != nil {
-		log.Printf("Device not found")
+	var found []models.Feature
+	if err := db.Where("id IN ?", featureIDs).Find(&found).Error; err != nil {
+		log.Printf("Failed to load features: %v", err)
 		return
 	}
 
-	var feature models.Feature
-	if err := db.First(&feature, featureID).Error; err != nil {
+	// Keep the features in the requested order
+	byID := make(map[uint]models.Feature, len(found))
+	for _, feature := range found {
+		byID[feature.ID] = feature
+	}
+	var features []models.Feature
+	for _, id := range featureIDs {
+		feature, ok := byID[id]
+		if !ok {
+			log.Printf("Feature %d not found", id)
+			continue
+		}
+		features = append(features, feature)
+	}
+	if len(features) == 0 {
 		log.Printf("Feature not found")
 		return
 	}
@@ -260,186 +312,422 @@ func RunTestInBackground(siteID, deviceID, featureID uint, email, password strin
 	log.Printf("Email: %s, Password: %s", email, password)
 
 	for _, browser := range browsers {
-		go func(browserType string) {
-			startTime := time.Now()
-
-			// Create initial result record
-			result := models.Result{
-				SiteID:    siteID,
-				DeviceID:  deviceID,
-				FeatureID: featureID,
-				Browser:   browserType,
-				Status:    "processing",
+		browserFeatures := features
+		if len(options.RerunOf) > 0 {
+			browserFeatures = nil
+			for _, feature := range features {
+				if _, ok := options.RerunOf[RerunKey(feature.ID, browser)]; ok {
+					browserFeatures = append(browserFeatures, feature)
+				}
 			}
+		}
+		if len(browserFeatures) == 0 {
+			continue
+		}
 
-			if err := db.Create(&result).Error; err != nil {
-				log.Printf("Failed to create result for %s: %v", browserType, err)
-				return
-			}
+		if run.SingleSession {
+			go runSession(db, run, browserFeatures, browser, email, password, options)
+			continue
+		}
+		for _, feature := range browserFeatures {
+			go runSession(db, run, []models.Feature{feature}, browser, email, password, options)
+		}
+	}
+}
 
-			// Create a new BrowserStack runner
-			runner := NewBrowserStackRunner()
+// runSession tests the features one after another in one browser session, saving each to its own result
+func runSession(db *gorm.DB, run models.Run, features []models.Feature, browserType, email, password string, options RunOptions) {
+	startTime := time.Now()
+
+	// Create initial result records, queued features show as processing until their turn
+	results := make([]models.Result, len(features))
+	for i, feature := range features {
+		results[i] = models.Result{
+			RunID:     &run.ID,
+			SiteID:    run.SiteID,
+			DeviceID:  run.DeviceID,
+			FeatureID: feature.ID,
+			Browser:   browserType,
+			Status:    "processing",
+			Attempt:   1,
+		}
+		if originalID, ok := options.RerunOf[RerunKey(feature.ID, browserType)]; ok {
+			originalID := originalID
+			results[i].RerunOfID = &originalID
+		}
+	}
 
-			// Initialize the runner with specified browser
-			if err := runner.Initialize(browserType); err != nil {
-				runner.logError(result.ID, time.Since(startTime), fmt.Sprintf("Failed to initialize %s runner: %v", browserType, err))
-				return
-			}
-			defer runner.Close()
+	if err := db.Create(&results).Error; err != nil {
+		log.Printf("Failed to create result for %s: %v", browserType, err)
+		return
+	}
 
-			// Log test start
-			if err := runner.LogTestStep(fmt.Sprintf("Test started for %s - Initializing browser", browserType)); err != nil {
-				log.Printf("Warning: Failed to log test start for %s: %v", browserType, err)
-			}
+	// Create a new BrowserStack runner, its browser session is opened by the first attempt
+	runner := NewBrowserStackRunner()
+	runner.config.CaptureHAR = options.CaptureHAR
+	defer runner.Close()
 
-			// Take screenshot before login
-			beforeLoginScreenshot, err := runner.TakeScreenshot()
-			if err != nil {
-				log.Printf("Warning: Failed to take before login screenshot for %s: %v", browserType, err)
-			} else {
-				log.Printf("Before login screenshot saved for %s: %s", browserType, beforeLoginScreenshot)
-				if err := runner.LogTestStep(fmt.Sprintf("Screenshot taken before login: %s", beforeLoginScreenshot)); err != nil {
-					log.Printf("Warning: Failed to log screenshot for %s: %v", browserType, err)
-				}
-			}
+	var state sessionState
+	for i, feature := range features {
+		result := results[i]
+		policy := run.RetryPolicyFor(feature)
+
+		for attempt := 1; ; attempt++ {
+			initErr := runner.runAttempt(db, run, feature, &result, &state, browserType, email, password)
 
-			// Navigate to login page
-			log.Printf("Navigating to login page using %s...", browserType)
-			if err := runner.LogTestStep(fmt.Sprintf("Navigating to login page using %s", browserType)); err != nil {
-				log.Printf("Warning: Failed to log navigation attempt for %s: %v", browserType, err)
+			retry, ok := runner.nextAttempt(db, run, result, attempt, policy, &state)
+			if ok {
+				result = retry
+				continue
 			}
 
-			if err := runner.NavigateToLoginPage(site.Name, email, password); err != nil {
-				logMsg := fmt.Sprintf("Failed to navigate to login page using %s: %v", browserType, err)
-				runner.logError(result.ID, time.Since(startTime), logMsg)
-				if err := runner.LogTestStep(logMsg); err != nil {
-					log.Printf("Warning: Failed to log navigation failure for %s: %v", browserType, err)
+			// Without a browser session the remaining features cannot run either
+			if initErr != nil {
+				for _, remaining := range results[i+1:] {
+					runner.logError(remaining.ID, time.Since(startTime), fmt.Sprintf("Failed to initialize %s runner: %v", browserType, initErr))
 				}
 				return
 			}
+			break
+		}
+	}
 
-			if err := runner.LogTestStep(fmt.Sprintf("Successfully navigated to login page using %s", browserType)); err != nil {
-				log.Printf("Warning: Failed to log successful navigation for %s: %v", browserType, err)
-			}
-			log.Printf("Successfully navigated to login page using %s!", browserType)
+	// Keep the session alive for a while to verify everything is working
+	log.Printf("Keeping %s session alive for 5 seconds...", browserType)
+	if err := runner.LogTestStep(fmt.Sprintf("Keeping %s session alive for 5 seconds", browserType)); err != nil {
+		log.Printf("Warning: Failed to log session wait for %s: %v", browserType, err)
+	}
+	time.Sleep(5 * time.Second)
+}
 
-			// Take screenshot of login page
-			runner.TakeStepScreenshot(db, result.ID, browserType, "Login Page")
+// sessionState is what a session knows about its browser between the features it tests
+type sessionState struct {
+	started    bool
+	used       bool
+	loggedIn   bool
+	mainWindow string
+}
 
-			// Perform login
-			log.Printf("Attempting to login to " + site.Name + " using %s...", browserType)
-			if err := runner.LogTestStep(fmt.Sprintf("Attempting to login to " + site.Name + " using %s", browserType)); err != nil {
-				log.Printf("Warning: Failed to log login attempt for %s: %v", browserType, err)
-			}
+// runAttempt tests a feature once, opening the browser session and logging in first when needed.
+// The error of opening the session is returned after failing the result with it.
+func (r *BrowserStackRunner) runAttempt(db *gorm.DB, run models.Run, feature models.Feature, result *models.Result, state *sessionState, browserType, email, password string) error {
+	startTime := time.Now()
 
-			if err := runner.LoginHandler(site.Name, email, password); err != nil {
-				logMsg := fmt.Sprintf("Login failed for %s: %v", browserType, err)
-				runner.logError(result.ID, time.Since(startTime), logMsg)
-				if err := runner.LogTestStep(logMsg); err != nil {
-					log.Printf("Warning: Failed to log login failure for %s: %v", browserType, err)
-				}
-				return
+	if !state.started {
+		// Initialize the runner with specified browser
+		if err := r.Initialize(browserType); err != nil {
+			r.logError(result.ID, time.Since(startTime), fmt.Sprintf("Failed to initialize %s runner: %v", browserType, err))
+			r.closeSession()
+			return err
+		}
+		state.started = true
+
+		// Log test start
+		if err := r.LogTestStep(fmt.Sprintf("Test started for %s - Initializing browser", browserType)); err != nil {
+			log.Printf("Warning: Failed to log test start for %s: %v", browserType, err)
+		}
+
+		mainWindow, err := r.driver.CurrentWindowHandle()
+		if err != nil {
+			log.Printf("Warning: Failed to get main window for %s: %v", browserType, err)
+		}
+		state.mainWindow = mainWindow
+	} else if state.used {
+		// Start every later feature from the home page, isolated from what the previous one left open
+		r.resetSession(run.Site.Name, state.mainWindow)
+	}
+	state.used = true
+
+	// Features testing the login itself start from a logged-out browser
+	if feature.FreshLogin && state.loggedIn {
+		r.clearBrowserState()
+		state.loggedIn = false
+	}
+
+	// The Invalid Password feature submits its own login form
+	if feature.Name != "Invalid Password" && !state.loggedIn {
+		if err := r.login(db, run.Site, email, password, result.ID, !feature.FreshLogin); err != nil {
+			logMsg := err.Error()
+			r.logError(result.ID, time.Since(startTime), logMsg)
+			if err := r.LogTestStep(logMsg); err != nil {
+				log.Printf("Warning: Failed to log login failure for %s: %v", browserType, err)
 			}
-			
-			if err := runner.LogTestStep(fmt.Sprintf("Login successful for %s", browserType)); err != nil {
-				log.Printf("Warning: Failed to log successful login for %s: %v", browserType, err)
+			r.flushResult(db, result.ID)
+			return nil
+		}
+		state.loggedIn = true
+	}
+
+	r.testFeature(db, run, feature, result, email, startTime)
+
+	// These features leave the browser logged out
+	if feature.Name == "Logout" || feature.Name == "Invalid Password" {
+		state.loggedIn = false
+	}
+
+	return nil
+}
+
+// login logs in to the site, reusing the saved login of the account when reuse is set,
+// and records the login steps and screenshots on the result
+func (r *BrowserStackRunner) login(db *gorm.DB, site models.Site, email, password string, resultID uint, reuse bool) error {
+	browserType := r.browserType
+
+	// Reuse the saved login of the account unless the feature tests the login itself
+	if reuse {
+		restored, err := r.RestoreSession(db, site, email)
+		if err != nil {
+			log.Printf("Warning: Failed to restore saved session for %s: %v", browserType, err)
+		}
+		if restored {
+			if err := r.LogTestStep(fmt.Sprintf("Reused saved login session for %s", browserType)); err != nil {
+				log.Printf("Warning: Failed to log session reuse for %s: %v", browserType, err)
 			}
-			log.Printf("Login successful for %s!", browserType)
-
-			// Take screenshot after login -- home page screenshot
-			runner.TakeStepScreenshot(db, result.ID, browserType, "After Successful Login")
-
-			// Failed test feature flag
-			isFailed := false
-			logMsg := ""
-
-			// Test Chat Functionality
-			if feature.Name == "Chat Functionality" {
-				if err := runner.ChatFunctionality(db, site, device, feature, browserType, result.ID, startTime); err != nil {
-					logMsg = fmt.Sprintf("%v", err)
-					log.Printf("Warning: Failed to test chat functionality for Result ID %d: %v", result.ID, err)
-					runner.logError(result.ID, time.Since(startTime), fmt.Sprintf("%v", err))
-					isFailed = true
-				}
-			} else if feature.Name == "Scrolling Home Page" {
-				if err := runner.ScrollingHomePage(db, site, device, feature, browserType, result.ID, startTime); err != nil {
-					logMsg = fmt.Sprintf("%v", err)
-					log.Printf("Warning: Failed to test scrolling home page for Result ID %d: %v", result.ID, err)
-					runner.logError(result.ID, time.Since(startTime), fmt.Sprintf("%v", err))
-					isFailed = true
-				}
-			} else if feature.Name == "Age Verification" {
-				if err := runner.AgeVerification(site.Name, feature.Name, browserType, result.ID, db); err != nil {
-					logMsg = fmt.Sprintf("%v", err)
-					log.Printf("Warning: Failed to test age verification for Result ID %d: %v", result.ID, logMsg)
-					runner.logError(result.ID, time.Since(startTime), logMsg)
-					isFailed = true
-				}
-			} else if feature.Name == "Premium Subscription" {
-				if err := runner.PremiumSubscription(site.Name, feature.Name, browserType, result.ID, db); err != nil {
-					logMsg = fmt.Sprintf("%v", err)
-					log.Printf("Warning: Failed to test premium subscription for Result ID %d: %v", result.ID, logMsg)
-					runner.logError(result.ID, time.Since(startTime), logMsg)
-					isFailed = true
-				}
-			} else if feature.Name == "iFrame Slot Machine Games" {
-				if err := runner.iFrameSlotMachineGames(site.Name, feature.Name, browserType, result.ID, db); err != nil {
-					logMsg = fmt.Sprintf("%v", err)
-					log.Printf("Warning: Failed to test iFrame slot machine games for Result ID %d: %v", result.ID, logMsg)
-					runner.logError(result.ID, time.Since(startTime), logMsg)
-					isFailed = true
+			r.TakeStepScreenshot(db, resultID, browserType, "After Restoring Session")
+			return nil
+		}
+	}
+
+	// Take screenshot before login
+	beforeLoginScreenshot, err := r.TakeScreenshot()
+	if err != nil {
+		log.Printf("Warning: Failed to take before login screenshot for %s: %v", browserType, err)
+	} else {
+		log.Printf("Before login screenshot saved for %s: %s", browserType, beforeLoginScreenshot)
+		if err := r.LogTestStep(fmt.Sprintf("Screenshot taken before login: %s", beforeLoginScreenshot)); err != nil {
+			log.Printf("Warning: Failed to log screenshot for %s: %v", browserType, err)
+		}
+	}
+
+	// Navigate to login page
+	log.Printf("Navigating to login page using %s...", browserType)
+	if err := r.LogTestStep(fmt.Sprintf("Navigating to login page using %s", browserType)); err != nil {
+		log.Printf("Warning: Failed to log navigation attempt for %s: %v", browserType, err)
+	}
+
+	if err := r.NavigateToLoginPage(site.Name, email, password); err != nil {
+		return fmt.Errorf("Failed to navigate to login page using %s: %v", browserType, err)
+	}
+
+	if err := r.LogTestStep(fmt.Sprintf("Successfully navigated to login page using %s", browserType)); err != nil {
+		log.Printf("Warning: Failed to log successful navigation for %s: %v", browserType, err)
+	}
+	log.Printf("Successfully navigated to login page using %s!", browserType)
+
+	// Take screenshot of login page
+	r.TakeStepScreenshot(db, resultID, browserType, "Login Page")
+
+	// Perform login
+	log.Printf("Attempting to login to " + site.Name + " using %s...", browserType)
+	if err := r.LogTestStep(fmt.Sprintf("Attempting to login to " + site.Name + " using %s", browserType)); err != nil {
+		log.Printf("Warning: Failed to log login attempt for %s: %v", browserType, err)
+	}
+
+	if err := r.LoginHandler(site.Name, email, password); err != nil {
+		return fmt.Errorf("Login failed for %s: %v", browserType, err)
+	}
+
+	if err := r.LogTestStep(fmt.Sprintf("Login successful for %s", browserType)); err != nil {
+		log.Printf("Warning: Failed to log successful login for %s: %v", browserType, err)
+	}
+	log.Printf("Login successful for %s!", browserType)
+
+	// Take screenshot after login -- home page screenshot
+	r.TakeStepScreenshot(db, resultID, browserType, "After Successful Login")
+
+	// Save the login for the next runs of the account
+	if err := r.SaveSession(db, site.ID, email); err != nil {
+		log.Printf("Warning: Failed to save login session for %s: %v", browserType, err)
+	}
+
+	return nil
+}
+
+// resetSession closes the windows and leaves the frames a feature opened and returns to the home page
+func (r *BrowserStackRunner) resetSession(siteName string, mainWindow string) {
+	if mainWindow != "" {
+		if handles, err := r.WindowHandles(); err == nil {
+			for _, handle := range handles {
+				if handle == mainWindow {
+					continue
 				}
-			} else {
-				// the rest function has not done yet
-				logMsg = fmt.Sprintf("%s feature has not been implemented yet", feature.Name)
-				runner.logError(result.ID, time.Since(startTime), logMsg)
-				if err := runner.LogTestStep(logMsg); err != nil {
-					log.Printf("%s feature has not been implemented yet", feature.Name)
+				if err := r.CloseWindow(handle, mainWindow); err != nil {
+					log.Printf("Warning: Failed to close window for %s: %v", r.browserType, err)
 				}
-				isFailed = true
 			}
+		}
+		if err := r.SwitchToWindow(mainWindow); err != nil {
+			log.Printf("Warning: Failed to return to main window for %s: %v", r.browserType, err)
+		}
+	}
 
-			if isFailed {
-				// Take failed screenshot
-				if logMsg == "" {
-					logMsg = fmt.Sprintf("Failed to test %s", feature.Name)
-				}
-				runner.TakeStepScreenshot(db, result.ID, browserType, logMsg)
-				return
-			}
+	if err := r.SwitchToDefaultContent(); err != nil {
+		log.Printf("Warning: Failed to leave frames for %s: %v", r.browserType, err)
+	}
 
-			// Keep the session alive for a while to verify everything is working
-			log.Printf("Keeping %s session alive for 5 seconds...", browserType)
-			if err := runner.LogTestStep(fmt.Sprintf("Keeping %s session alive for 5 seconds", browserType)); err != nil {
-				log.Printf("Warning: Failed to log session wait for %s: %v", browserType, err)
-			}
-			time.Sleep(5 * time.Second)
-
-			// Calculate duration
-			duration := time.Since(startTime)
-
-			// savedVideoPath, err := runner.SaveVideo(result.ID)
-			// if err != nil || savedVideoPath == "" {
-			// 	log.Printf("Warning: Failed to save video for Result ID %d: %v", resultID, err)
-			// }
-
-			// Update result status to passed
-			if err := db.Model(&result).Updates(map[string]interface{}{
-				"status": "passed",
-				"duration": duration.Seconds(),
-				// "video_path": savedVideoPath,
-			}).Error; err != nil {
-				log.Printf("Warning: Failed to update result status for %s: %v", browserType, err)
-			}
+	if err := r.driver.Get("https://" + siteName); err != nil {
+		log.Printf("Warning: Failed to return to home page for %s: %v", r.browserType, err)
+	}
 
-			if err := runner.LogTestStep(fmt.Sprintf("Test completed successfully for %s in %v", browserType, duration)); err != nil {
-				log.Printf("Warning: Failed to log test completion for %s: %v", browserType, err)
-			}
-			log.Printf("Test completed successfully for %s in %v!", browserType, duration)
-		}(browser)
+	// Wait for home page to load and be ready
+	time.Sleep(3 * time.Second)
+}
+
+// testFeature tests a feature on the logged-in session and saves the outcome to its result
+func (r *BrowserStackRunner) testFeature(db *gorm.DB, run models.Run, feature models.Feature, result *models.Result, email string, startTime time.Time) {
+	browserType := r.browserType
+
+	// Save the network capture, page timings and assertions of this feature with its result
+	defer r.flushResult(db, result.ID)
+
+	// A feature whose hooks can't be loaded is not tested, its setup may be needed to leave the account clean
+	hooks, err := loadFeatureHooks(db, feature.ID)
+	if err != nil {
+		logMsg := fmt.Sprintf("Setup failed: %v", err)
+		r.logError(result.ID, time.Since(startTime), logMsg)
+		return
+	}
+
+	// Teardown runs even when the setup or the test fails, its outcome is kept apart from the result status
+	hookContext := HookContext{DB: db, Site: run.Site, Feature: feature, Email: email, ResultID: result.ID}
+	defer func() {
+		if err := r.runHooks(models.HookPhaseAfter, hooks.After, hookContext); err != nil {
+			log.Printf("Warning: Teardown of %s failed for Result ID %d: %v", feature.Name, result.ID, err)
+		}
+	}()
+
+	if err := r.runHooks(models.HookPhaseBefore, hooks.Before, hookContext); err != nil {
+		logMsg := fmt.Sprintf("Setup failed: %v", err)
+		r.logError(result.ID, time.Since(startTime), logMsg)
+		r.TakeStepScreenshot(db, result.ID, browserType, logMsg)
+		return
+	}
+
+	if err := r.runFeature(db, run.Site, run.Device, feature, email, result.ID, startTime); err != nil {
+		logMsg := fmt.Sprintf("%v", err)
+		r.logError(result.ID, time.Since(startTime), logMsg)
+
+		// Take failed screenshot
+		r.TakeStepScreenshot(db, result.ID, browserType, logMsg)
+		return
 	}
+
+	// Calculate duration
+	duration := time.Since(startTime)
+
+	// savedVideoPath, err := r.SaveVideo(result.ID)
+	// if err != nil || savedVideoPath == "" {
+	// 	log.Printf("Warning: Failed to save video for Result ID %d: %v", resultID, err)
+	// }
+
+	// Check page timings against the performance budgets and previous runs
+	r.storeMetrics(db, result.ID)
+	status, perfMessages, err := r.EvaluatePerformance(db, run.SiteID, result.ID)
+	if err != nil {
+		log.Printf("Warning: Failed to evaluate performance for %s: %v", browserType, err)
+	}
+	for _, message := range perfMessages {
+		if err := r.LogTestStep(message); err != nil {
+			log.Printf("Warning: Failed to log performance check for %s: %v", browserType, err)
+		}
+	}
+
+	// Warnings raised by the feature downgrade a passed result
+	if len(r.warnings) > 0 && status == "passed" {
+		status = "warning"
+	}
+	messages := append(r.warnings, perfMessages...)
+
+	// Update result status to passed, or to the status of the warnings and exceeded budgets
+	updates := map[string]interface{}{
+		"status": status,
+		"duration": duration.Seconds(),
+		"error_log": strings.Join(messages, "\n"),
+		// "video_path": savedVideoPath,
+	}
+	// The failure is classified and signed from the budgets that failed it, not the warnings before them
+	failure := strings.Join(FailedBudgetMessages(perfMessages), "\n")
+	if status == "failed" {
+		updates["failure_category"] = ClassifyFailure(failure)
+	}
+	if err := db.Model(result).Updates(updates).Error; err != nil {
+		log.Printf("Warning: Failed to update result status for %s: %v", browserType, err)
+	} else if status == "failed" {
+		openTriage(db, models.Result{
+			ID:              result.ID,
+			SiteID:          result.SiteID,
+			FeatureID:       result.FeatureID,
+			ErrorLog:        failure,
+			FailureCategory: updates["failure_category"].(string),
+		})
+	}
+
+	if err := r.LogTestStep(fmt.Sprintf("Test completed with status %s for %s in %v", status, browserType, duration)); err != nil {
+		log.Printf("Warning: Failed to log test completion for %s: %v", browserType, err)
+	}
+	log.Printf("Test completed with status %s for %s in %v!", status, browserType, duration)
+}
+
+// flushResult saves what was buffered during a feature with its result and clears its warnings
+func (r *BrowserStackRunner) flushResult(db *gorm.DB, resultID uint) {
+	// Save the network capture before the next feature reads the browser log
+	if r.config.CaptureHAR && harLoggingPrefsKey(r.browserType) != "" {
+		r.captureHAR(db, resultID)
+	}
+
+	// Save the page timings and metrics collected during the test
+	r.storeMetrics(db, resultID)
+
+	// Save the failed hard and soft assertions
+	r.storeAssertionFailures(db, resultID)
+
+	r.warnings = nil
 }
 
+// runFeature runs the test of a feature on the current session
+func (r *BrowserStackRunner) runFeature(db *gorm.DB, site models.Site, device models.Device, feature models.Feature, email string, resultID uint, startTime time.Time) error {
+	browserType := r.browserType
+
+	var err error
+	if feature.Name == "Chat Functionality" {
+		err = r.ChatFunctionality(db, site, device, feature, browserType, resultID, startTime)
+	} else if feature.Name == "Scrolling Home Page" {
+		err = r.ScrollingHomePage(db, site, device, feature, browserType, resultID, startTime)
+	} else if feature.Name == "Age Verification" {
+		err = r.AgeVerification(site.Name, feature.Name, browserType, resultID, db)
+	} else if feature.Name == "Premium Subscription" {
+		err = r.PremiumSubscription(site.Name, feature.Name, browserType, resultID, db)
+	} else if feature.Name == "iFrame Slot Machine Games" {
+		err = r.iFrameSlotMachineGames(site.Name, feature.Name, browserType, resultID, db)
+	} else if feature.Name == "Accessibility Audit" {
+		err = r.AccessibilityAudit(site.Name, feature.Name, browserType, resultID, db)
+	} else if feature.Name == "Link Check" {
+		err = r.LinkCheck(site.Name, feature.Name, browserType, resultID, db)
+	} else if feature.Name == "Infinite Scroll Feed" {
+		err = r.InfiniteScrollFeed(site.Name, device.Name, feature.Name, browserType, resultID, db)
+	} else if feature.Name == "Valid Login" {
+		err = r.ValidLogin(site.Name, feature.Name, browserType, resultID, db)
+	} else if feature.Name == "Invalid Password" {
+		err = r.InvalidPassword(site.Name, email, feature.Name, browserType, resultID, db)
+	} else if feature.Name == "Logout" {
+		err = r.Logout(site.Name, feature.Name, browserType, resultID, db)
+	} else if feature.Name == "Session Persistence" {
+		err = r.SessionPersistence(site.Name, feature.Name, browserType, resultID, db)
+	} else {
+		// the rest function has not done yet
+		logMsg := fmt.Sprintf("%s feature has not been implemented yet", feature.Name)
+		if err := r.LogTestStep(logMsg); err != nil {
+			log.Printf("%s feature has not been implemented yet", feature.Name)
+		}
+		return fmt.Errorf("%s", logMsg)
+	}
+
+	if err != nil {
+		log.Printf("Warning: Failed to test %s for Result ID %d: %v", strings.ToLower(feature.Name), resultID, err)
+	}
+
+	return err
+}
 // LoginHandler performs login to site
 func (r *BrowserStackRunner) LoginHandler(siteName string, email, password string) error {
 	if r.driver == nil {
@@ -447,7 +735,7 @@ func (r *BrowserStackRunner) LoginHandler(siteName string, email, password strin
 	}
 
 	// Find and click submit button
-	submitButton, err := r.driver.FindElement(selenium.ByCSSSelector, "#btn-register")
+	submitButton, err := r.driver.FindElement(selenium.ByCSSSelector, loginSubmitSelector)
 	if err != nil {
 		return fmt.Errorf("failed to find submit button: %v", err)
 	}
@@ -455,18 +743,22 @@ func (r *BrowserStackRunner) LoginHandler(siteName string, email, password strin
 		return fmt.Errorf("failed to click submit button: %v", err)
 	}
 
-	// Wait for login to complete and page to be ready
-	time.Sleep(10 * time.Second)
-
-	// Verify login success by checking if we're still on the login page
-	currentURL, err := r.driver.CurrentURL()
+	// Wait for login to complete by leaving the login page
+	loginPage := regexp.MustCompile(loginPageURL(siteName))
+	err = r.driver.WaitWithTimeoutAndInterval(func(wd selenium.WebDriver) (bool, error) {
+		currentURL, err := wd.CurrentURL()
+		if err != nil {
+			return false, nil
+		}
+		return !loginPage.MatchString(currentURL), nil
+	}, loginTimeout, 500*time.Millisecond)
 	if err != nil {
-		return fmt.Errorf("failed to get current URL: %v", err)
-	}
-	if currentURL == "https://" + siteName + "/login" {
-		return fmt.Errorf("login failed: still on login page")
+		return fmt.Errorf("login failed: still on login page after %v", loginTimeout)
 	}
 
+	// Wait for the page to be ready
+	time.Sleep(2 * time.Second)
+
 	return nil
 }
 
@@ -483,6 +775,7 @@ func (r *BrowserStackRunner) NavigateToLoginPage(siteName string, email, passwor
 
 	// Wait for the page to load
 	time.Sleep(2 * time.Second)
+	r.collectPageMetrics("Login Page")
 
 	// Click the Login Button
 	loginButton, err := r.driver.FindElement(selenium.ByCSSSelector, ".login-text")
@@ -553,6 +846,7 @@ func (r *BrowserStackRunner) NavigateToHomePage(siteName string) error {
 
 	// Wait for home page to load and be ready
 	time.Sleep(3 * time.Second)
+	r.collectPageMetrics("Home Page")
 
 	// Verify we're on the home page
 	currentURL, err := r.driver.CurrentURL()
@@ -579,6 +873,7 @@ func (r *BrowserStackRunner) NavigateToChatPage(siteName string) error {
 
 	// Wait for chat page to load and be ready
 	time.Sleep(3 * time.Second)
+	r.collectPageMetrics("Chat Page")
 
 	// Verify we're on the chat page
 	currentURL, err := r.driver.CurrentURL()
@@ -627,6 +922,7 @@ func (r *BrowserStackRunner) NavigateToOpenChat(siteName string) error {
 
 	// Wait for the open chat page to load
 	time.Sleep(5 * time.Second)
+	r.collectPageMetrics("Open Chat Page")
 
 	// Verify we're on the chat rest page
 	currentURL, err := r.driver.CurrentURL()
@@ -668,6 +964,8 @@ func (r *BrowserStackRunner) SendingMessageToChat(siteName string) error {
 		return fmt.Errorf("chat rest ID not found for site: %s", siteName)
 	}
 
+	messageList := chatMessageList(siteName)
+
 	// Find and fill message field
 	emailField, err := r.driver.FindElement(selenium.ByCSSSelector, ".v-field__input")
 	if err != nil {
@@ -676,7 +974,9 @@ func (r *BrowserStackRunner) SendingMessageToChat(siteName string) error {
 	if err := emailField.Clear(); err != nil {
 		return fmt.Errorf("failed to clear message field: %v", err)
 	}
-	if err := emailField.SendKeys("Chat send on " + time.Now().Format("2006-01-02 15:04:05")); err != nil {
+	// Make the message unique so it can be found in the conversation
+	message := fmt.Sprintf("Chat send on %s #%d", time.Now().Format("2006-01-02 15:04:05"), time.Now().UnixNano()%100000)
+	if err := emailField.SendKeys(message); err != nil {
 		return fmt.Errorf("failed to enter message: %v", err)
 	}
 
@@ -685,12 +985,43 @@ func (r *BrowserStackRunner) SendingMessageToChat(siteName string) error {
 	if err != nil {
 		return fmt.Errorf("failed to find send button: %v", err)
 	}
+	// Time the message from here to the server answer
+	if _, err := r.driver.ExecuteScript(chatSendHookScript, []interface{}{message}); err != nil {
+		log.Printf("Warning: Failed to hook chat requests for %s: %v", r.browserType, err)
+	}
 	if err := submitButton.Click(); err != nil {
 		return fmt.Errorf("failed to click send button: %v", err)
 	}
+	sentAt := time.Now()
+
+	// Wait for the sent message to appear in the conversation
+	if err := r.Expect().Within(chatMessageTimeout).TextContains(messageList, message); err != nil {
+		return fmt.Errorf("sent message did not appear in the conversation: %v", err)
+	}
+
+	// Record the round trip to the server answer, the message usually appears before it
+	roundTrip, err := r.waitForChatRoundTrip(5 * time.Second)
+	if err != nil {
+		log.Printf("Warning: Failed to read chat round trip for %s: %v", r.browserType, err)
+	}
+	if roundTrip > 0 {
+		r.recordMetric("Chat Message Round Trip", float64(roundTrip.Milliseconds()), "ms")
+	} else if err := r.LogTestStep("No request carrying the chat message was seen, round trip not recorded"); err != nil {
+		log.Printf("Warning: Failed to log chat round trip for %s: %v", r.browserType, err)
+	}
 
-	// Wait for send chat to complete and page to be ready
-	time.Sleep(20 * time.Second)
+	// Wait for the counterpart reply, when the conversation has one
+	reply, err := r.waitForChatReply(messageList, message, chatReplyTimeout())
+	if err != nil {
+		log.Printf("Warning: Failed to check chat reply for %s: %v", r.browserType, err)
+	} else if reply != "" {
+		r.recordMetric("Chat Reply Latency", float64(time.Since(sentAt).Milliseconds()), "ms")
+		if err := r.LogTestStep(fmt.Sprintf("Chat reply received: %s", reply)); err != nil {
+			log.Printf("Warning: Failed to log chat reply for %s: %v", r.browserType, err)
+		}
+	} else if err := r.LogTestStep("No chat reply received"); err != nil {
+		log.Printf("Warning: Failed to log chat reply for %s: %v", r.browserType, err)
+	}
 
 	// Verify we're on the chat rest page
 	currentURL, err := r.driver.CurrentURL()
@@ -780,41 +1111,68 @@ func (r *BrowserStackRunner) ChatFunctionality(db *gorm.DB, site models.Site, de
 
 // Scrolling Home Page
 func (r *BrowserStackRunner) ScrollingHomePage(db *gorm.DB, site models.Site, device models.Device, feature models.Feature, browserType string, resultID uint, startTime time.Time) error {
-	wheelCssSelector := ""
+	// Scroll container of the site, the window is scrolled when empty
+	scrollContainer := ""
 
 	if site.Name == "senti.live" {
-		wheelCssSelector = "root-observed"
-	}
-
-	if site.Name == "hothinge.com" {
-		wheelCssSelector = "layout-page"
+		scrollContainer = ".root-observed"
 	}
 
 	// If current site is shorts.senti.live or viblys.com
 	// Check the Pause and Play Video action
 	if site.Name == "shorts.senti.live" || site.Name == "viblys.com" {
-		wheelCssSelector = "video-feed"
+		scrollContainer = ".video-feed"
 		// After login, by default it will redirect to home page
 		// And the video will automatically play
 		// So we need to click on the Video to pause the video
 		// Click the Video Element
-		r.pauseVideo(db, resultID, browserType, startTime)
+		if err := r.pauseVideo(db, resultID, browserType, startTime); err != nil {
+			r.logError(resultID, time.Since(startTime), fmt.Sprintf("Failed to pause video: %v", err))
+			return err
+		}
 
 		// And then click on the Play Video button to play the video
 		// Click the Play Video Button
-		r.playVideo(db, resultID, browserType, startTime)
+		if err := r.playVideo(db, resultID, browserType, startTime); err != nil {
+			r.logError(resultID, time.Since(startTime), fmt.Sprintf("Failed to play video: %v", err))
+			return err
+		}
 
-		// Simulate wheel event with deltaY of 150
-		wheelScript := simulateWheelEvent(150, wheelCssSelector)
-		if _, err := r.driver.ExecuteScript(wheelScript, nil); err != nil {
-			r.logError(resultID, time.Since(startTime), fmt.Sprintf("Failed to simulate scroll event: %v", err))
+		// Remember the current video to verify the feed advances
+		before, err := r.VideoState()
+		if err != nil {
+			r.logError(resultID, time.Since(startTime), fmt.Sprintf("Failed to read video state: %v", err))
+			return err
+		}
+
+		// Swipe up to the next video on touch devices, scroll the wheel on desktop
+		if device.Name == "Mobile" || device.Name == "Tablet" {
+			if err := r.Swipe(scrollContainer, 0, -400, 300*time.Millisecond); err != nil {
+				r.logError(resultID, time.Since(startTime), fmt.Sprintf("Failed to swipe feed: %v", err))
+				return err
+			}
+		} else {
+			if err := r.Wheel(scrollContainer, 0, 800); err != nil {
+				r.logError(resultID, time.Since(startTime), fmt.Sprintf("Failed to scroll feed: %v", err))
+				return err
+			}
+		}
+
+		// Verify the feed advanced to a different video that plays
+		after, err := r.WaitForVideoPlaying(10 * time.Second)
+		if err != nil {
+			r.logError(resultID, time.Since(startTime), fmt.Sprintf("Next video did not play after scroll: %v", err))
+			return err
+		}
+		if after.Src == before.Src && after.Index == before.Index {
+			err := fmt.Errorf("feed did not advance to a different video after scroll: still on %s", before.Src)
+			r.logError(resultID, time.Since(startTime), err.Error())
 			return err
 		}
 	} else {
-		// Simulate scroll event with top 1000
-		scrollScript := simulateScrollEvent(site.Name, 1000, wheelCssSelector)
-		if _, err := r.driver.ExecuteScript(scrollScript, nil); err != nil {
-			r.logError(resultID, time.Since(startTime), fmt.Sprintf("Failed to simulate scroll event: %v", err))
+		// Scroll down the page by 1000 pixels
+		if err := r.ScrollBy(scrollContainer, 0, 1000); err != nil {
+			r.logError(resultID, time.Since(startTime), fmt.Sprintf("Failed to scroll page: %v", err))
 			return err
 		}
 	}
@@ -830,6 +1188,11 @@ func (r *BrowserStackRunner) ScrollingHomePage(db *gorm.DB, site models.Site, de
 
 // Pause Video
 func (r *BrowserStackRunner) pauseVideo(db *gorm.DB, resultID uint, browserType string, startTime time.Time) error {
+	// The video should be playing automatically before it can be paused
+	if _, err := r.WaitForVideoPlaying(10 * time.Second); err != nil {
+		return fmt.Errorf("video did not start playing automatically: %v", err)
+	}
+
 	videoElement, err := r.driver.FindElement(selenium.ByCSSSelector, ".video-player")
 	if err != nil {
 		return fmt.Errorf("failed to find .video-player element: %v", err)
@@ -837,7 +1200,11 @@ func (r *BrowserStackRunner) pauseVideo(db *gorm.DB, resultID uint, browserType
 	if err := videoElement.Click(); err != nil {
 		return fmt.Errorf("failed to click .video-player element: %v", err)
 	}
-	time.Sleep(1 * time.Second)
+
+	// Verify playback actually stopped
+	if _, err := r.WaitForVideoPaused(5 * time.Second); err != nil {
+		return err
+	}
 
 	// Take screenshot of pause video
 	r.TakeStepScreenshot(db, resultID, browserType, "Pause Video")
@@ -854,7 +1221,11 @@ func (r *BrowserStackRunner) playVideo(db *gorm.DB, resultID uint, browserType s
 	if err := playVideoButton.Click(); err != nil {
 		return fmt.Errorf("failed to click .play-button-overlay button: %v", err)
 	}
-	time.Sleep(2 * time.Second)
+
+	// Verify playback actually resumed
+	if _, err := r.WaitForVideoPlaying(10 * time.Second); err != nil {
+		return err
+	}
 
 	// Take screenshot of play video
 	r.TakeStepScreenshot(db, resultID, browserType, "Play Video")
@@ -862,37 +1233,6 @@ func (r *BrowserStackRunner) playVideo(db *gorm.DB, resultID uint, browserType s
 	return nil
 }
 
-// Simulate Scroll Event
-func simulateScrollEvent(siteName string, deltaY int, wheelCssSelector string) string {
-	if siteName == "senti.live" {
-		return fmt.Sprintf(`
-			document.getElementsByClassName('%s')[0].scrollTo({
-				top: %d,
-				behavior: 'smooth'
-			});
-		`, wheelCssSelector, deltaY)
-	}
-
-	// default scroll by browser window
-	return fmt.Sprintf(`
-		window.scrollTo({
-			top: %d,
-			behavior: 'smooth'
-		});
-	`, deltaY)
-}
-
-// Simulate Wheel Event
-func simulateWheelEvent(deltaY int, wheelCssSelector string) string {
-	return fmt.Sprintf(`
-		let wheelEvent = new WheelEvent('wheel', {
-			deltaY: %d,
-			deltaMode: 1
-		});
-		document.getElementsByClassName('%s')[0].dispatchEvent(wheelEvent);	
-	`, deltaY, wheelCssSelector)
-}
-
 // Age Verfication
 func (r *BrowserStackRunner) AgeVerification(siteName string, featureName string, browserType string, resultID uint, db *gorm.DB) error {
 	if r.driver == nil {
@@ -919,27 +1259,8 @@ func (r *BrowserStackRunner) AgeVerification(siteName string, featureName string
 		time.Sleep(1 * time.Second)
 
 		// Search <p> element with innerHTML AGE VERIFICATION
-		elements, err := r.driver.FindElements(selenium.ByTagName, "p")
-		if err != nil {
-			return fmt.Errorf("Failed to find <p> elements: %v", err)
-		}
-
-		isAgeVerificationPopup := false
-
-		for _, element := range elements {
-			text, err := element.Text()
-			if err != nil {
-				return fmt.Errorf("Failed to get innterHTML element: %v", err)
-			}
-			log.Printf("Element text: %s", text)
-			if strings.ToLower(text) == "age verification" {
-				isAgeVerificationPopup = true
-				break
-			}
-		}
-
-		if !isAgeVerificationPopup {
-			return fmt.Errorf("Failed to find age verification form")
+		if err := r.Expect().TextEquals("p", "age verification"); err != nil {
+			return fmt.Errorf("Failed to find age verification form: %v", err)
 		}
 
 		// Check the Age Verification Form
@@ -996,6 +1317,11 @@ func (r *BrowserStackRunner) AgeVerification(siteName string, featureName string
 
 		// Take screenshot of submit age verification
 		r.TakeStepScreenshot(db, resultID, browserType, fmt.Sprintf("Submit %s", featureName))
+
+		// The popup should close once verified, a lingering form only warns
+		if err := r.SoftExpect().ElementCount(".btn-chat-profile", 0); err != nil {
+			return err
+		}
 	}
 
 	return nil
@@ -1027,27 +1353,8 @@ func (r *BrowserStackRunner) PremiumSubscription(siteName string, featureName st
 		time.Sleep(1 * time.Second)
 
 		// Search <h2> element with innerHTML contains "Go premium and connect"
-		elements, err := r.driver.FindElements(selenium.ByTagName, "h2")
-		if err != nil {
-			return fmt.Errorf("Failed to find <h2> elements: %v", err)
-		}
-
-		isPremiumSubscription := false
-
-		for _, element := range elements {
-			text, err := element.Text()
-			if err != nil {
-				return fmt.Errorf("Failed to get innterHTML element: %v", err)
-			}
-			log.Printf("Element text: %s", text)
-			if strings.Contains(strings.ToLower(text), "go premium and connect") {
-				isPremiumSubscription = true
-				break
-			}
-		}
-
-		if !isPremiumSubscription {
-			return fmt.Errorf("Failed to find premium subscription form")
+		if err := r.Expect().TextContains("h2", "go premium and connect"); err != nil {
+			return fmt.Errorf("Failed to find premium subscription form: %v", err)
 		}
 
 		// Take screenshot of premium subscription form
@@ -1101,6 +1408,11 @@ func (r *BrowserStackRunner) PremiumSubscription(siteName string, featureName st
 
 		// Take screenshot of premium subscription completed
 		r.TakeStepScreenshot(db, resultID, browserType, fmt.Sprintf("%s Completed", featureName))
+
+		// The confirmation dialog should close once subscribed, a lingering dialog only warns
+		if err := r.SoftExpect().Within(10 * time.Second).ElementCount(".payment-confirmation-dialog", 0); err != nil {
+			return err
+		}
 	}
 	
 	return nil
@@ -1128,6 +1440,7 @@ func (r *BrowserStackRunner) iFrameSlotMachineGames(siteName string, featureName
 
 		// Wait for the page to load
 		time.Sleep(5 * time.Second)
+		r.collectPageMetrics("Store Page")
 
 		// Take screenshot of store page
 		r.TakeStepScreenshot(db, resultID, browserType, "Store Page")
@@ -1142,21 +1455,48 @@ func (r *BrowserStackRunner) iFrameSlotMachineGames(siteName string, featureName
 			return fmt.Errorf("No buttons found on the store page.")
 		}
 
-		// Open the second button (currently Birdy Trick games)
-		openButton := openButtons[1]
+		originalWindow, err := r.driver.CurrentWindowHandle()
+		if err != nil {
+			return fmt.Errorf("Failed to get current window: %v", err)
+		}
+		existingWindows, err := r.WindowHandles()
+		if err != nil {
+			return err
+		}
 
-		if openButton == nil {
-			return fmt.Errorf("Failed to find open game button")
+		// Open the second button (currently Birdy Trick games)
+		opened := false
+		if len(openButtons) > 1 {
+			if err := openButtons[1].Click(); err == nil {
+				opened = true
+			}
 		}
 
-		if err := openButton.Click(); err != nil {
-			// If error on click button, navigate to birdy trick game page
+		if !opened {
+			// If the button is missing or can't be clicked, navigate to birdy trick game page
 			if err := r.driver.Get("https://" + siteName + "/game/birdy-trick"); err != nil {
 				return fmt.Errorf("Failed to navigate to birdy trick game page: %v", err)
 			}
 		}
 
-		time.Sleep(10 * time.Second)
+		// The game may open in a new tab
+		if gameWindow, err := r.WaitForNewWindow(existingWindows, 3*time.Second); err == nil {
+			if err := r.SwitchToWindow(gameWindow); err != nil {
+				return err
+			}
+			defer func() {
+				if err := r.CloseWindow(gameWindow, originalWindow); err != nil {
+					log.Printf("Warning: Failed to close game window for %s: %v", browserType, err)
+				}
+			}()
+		}
+
+		// Enter the game iframe and wait for the game canvas to render
+		if err := r.WithinFrame("iframe", 20*time.Second, func() error {
+			return r.WaitForCanvas(20 * time.Second)
+		}); err != nil {
+			return fmt.Errorf("Failed to load game inside iframe: %v", err)
+		}
 
 		// Take screenshot of iframe slot machine games
 		r.TakeStepScreenshot(db, resultID, browserType, featureName)
@@ -1165,6 +1505,14 @@ func (r *BrowserStackRunner) iFrameSlotMachineGames(siteName string, featureName
 	return nil
 }
 
+// addWarning records a non-fatal problem that ends a passed result as warning
+func (r *BrowserStackRunner) addWarning(message string) {
+	r.warnings = append(r.warnings, message)
+	if err := r.LogTestStep(fmt.Sprintf("Warning: %s", message)); err != nil {
+		log.Printf("Warning: Failed to log warning for %s: %v", r.browserType, err)
+	}
+}
+
 // Take Step Screenshot
 func (r *BrowserStackRunner) TakeStepScreenshot(db *gorm.DB, resultID uint, browserType string, featureName string) {
 	// Take screenshot
@@ -1211,9 +1559,13 @@ func (r *BrowserStackRunner) logError(resultID uint, duration time.Duration, err
 	result.Duration = duration.Seconds()
 	// result.VideoPath = savedVideoPath
 	result.ErrorLog = errorMsg
+	result.FailureCategory = ClassifyFailure(errorMsg)
 	if err := db.Save(&result).Error; err != nil {
 		log.Printf("Failed to update result with error: %v", err)
+		return
 	}
+
+	openTriage(db, result)
 }
 
 // Save Video to Videos Folder
@@ -0,0 +1,253 @@
+package testrunner
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"time"
+
+	"github.com/tebeka/selenium"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"qa-automation-system/backend/models"
+)
+
+// localStorageReadScript returns the local storage of the current page as an object
+const localStorageReadScript = `
+	var data = {};
+	for (var i = 0; i < window.localStorage.length; i++) {
+		var key = window.localStorage.key(i);
+		data[key] = window.localStorage.getItem(key);
+	}
+	return data;
+`
+
+// localStorageWriteScript copies the entries of the object argument into local storage
+const localStorageWriteScript = `
+	var data = arguments[0] || {};
+	Object.keys(data).forEach(function(key) {
+		window.localStorage.setItem(key, data[key]);
+	});
+`
+
+// sessionTTL returns how long a saved login is reused, from AUTH_SESSION_TTL in minutes
+func sessionTTL() time.Duration {
+	return time.Duration(envInt("AUTH_SESSION_TTL", 60)) * time.Minute
+}
+
+// sessionCipher returns the cipher the saved logins are encrypted with, keyed by AUTH_SESSION_KEY,
+// or nil when no key is set and logins are not saved
+func sessionCipher() (cipher.AEAD, error) {
+	secret := os.Getenv("AUTH_SESSION_KEY")
+	if secret == "" {
+		return nil, nil
+	}
+
+	key := sha256.Sum256([]byte(secret))
+	block, err := aes.NewCipher(key[:])
+	if err != nil {
+		return nil, fmt.Errorf("failed to create session cipher: %v", err)
+	}
+	return cipher.NewGCM(block)
+}
+
+// sealSessionData encrypts saved login data, prefixing it with its nonce
+func sealSessionData(aead cipher.AEAD, data []byte) (string, error) {
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", fmt.Errorf("failed to create nonce: %v", err)
+	}
+	return base64.StdEncoding.EncodeToString(aead.Seal(nonce, nonce, data, nil)), nil
+}
+
+// openSessionData decrypts saved login data sealed by sealSessionData
+func openSessionData(aead cipher.AEAD, sealed string) ([]byte, error) {
+	data, err := base64.StdEncoding.DecodeString(sealed)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < aead.NonceSize() {
+		return nil, fmt.Errorf("sealed data too short")
+	}
+	return aead.Open(nil, data[:aead.NonceSize()], data[aead.NonceSize():], nil)
+}
+
+// SaveSession saves the cookies and local storage of the logged-in page for later runs of the account,
+// encrypted with AUTH_SESSION_KEY. Nothing is saved without a key.
+func (r *BrowserStackRunner) SaveSession(db *gorm.DB, siteID uint, email string) error {
+	if r.driver == nil {
+		return fmt.Errorf("driver not initialized")
+	}
+
+	ttl := sessionTTL()
+	if ttl <= 0 {
+		return nil
+	}
+
+	aead, err := sessionCipher()
+	if err != nil {
+		return err
+	}
+	if aead == nil {
+		return nil
+	}
+
+	cookies, err := r.driver.GetCookies()
+	if err != nil {
+		return fmt.Errorf("failed to read cookies: %v", err)
+	}
+	cookieData, err := json.Marshal(cookies)
+	if err != nil {
+		return fmt.Errorf("failed to encode cookies: %v", err)
+	}
+
+	storage, err := r.driver.ExecuteScript(localStorageReadScript, nil)
+	if err != nil {
+		return fmt.Errorf("failed to read local storage: %v", err)
+	}
+	storageData, err := json.Marshal(storage)
+	if err != nil {
+		return fmt.Errorf("failed to encode local storage: %v", err)
+	}
+
+	sealedCookies, err := sealSessionData(aead, cookieData)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt cookies: %v", err)
+	}
+	sealedStorage, err := sealSessionData(aead, storageData)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt local storage: %v", err)
+	}
+
+	session := models.AuthSession{
+		SiteID:       siteID,
+		Email:        email,
+		Cookies:      sealedCookies,
+		LocalStorage: sealedStorage,
+		ExpiresAt:    time.Now().Add(ttl),
+	}
+	if err := db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "site_id"}, {Name: "email"}},
+		DoUpdates: clause.AssignmentColumns([]string{"cookies", "local_storage", "expires_at", "updated_at"}),
+	}).Create(&session).Error; err != nil {
+		return fmt.Errorf("failed to save session: %v", err)
+	}
+
+	return nil
+}
+
+// RestoreSession injects the saved login of the account and reports whether the site accepted it,
+// showing its logged-in marker (see AssertLoggedIn).
+// A rejected session is removed so the caller can fall back to logging in through the form.
+func (r *BrowserStackRunner) RestoreSession(db *gorm.DB, site models.Site, email string) (bool, error) {
+	if r.driver == nil {
+		return false, fmt.Errorf("driver not initialized")
+	}
+
+	aead, err := sessionCipher()
+	if err != nil {
+		return false, err
+	}
+	if aead == nil {
+		return false, nil
+	}
+
+	var session models.AuthSession
+	err = db.Where("site_id = ? AND email = ? AND expires_at > ?", site.ID, email, time.Now()).First(&session).Error
+	if err == gorm.ErrRecordNotFound {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to load session: %v", err)
+	}
+
+	// A session saved with another key can't be decrypted and is dropped
+	cookieData, err := openSessionData(aead, session.Cookies)
+	if err != nil {
+		r.InvalidateSession(db, site.ID, email)
+		return false, fmt.Errorf("failed to decrypt saved cookies: %v", err)
+	}
+	var cookies []selenium.Cookie
+	if err := json.Unmarshal(cookieData, &cookies); err != nil {
+		r.InvalidateSession(db, site.ID, email)
+		return false, fmt.Errorf("failed to parse saved cookies: %v", err)
+	}
+	var storage map[string]interface{}
+	if session.LocalStorage != "" {
+		storageData, err := openSessionData(aead, session.LocalStorage)
+		if err != nil {
+			r.InvalidateSession(db, site.ID, email)
+			return false, fmt.Errorf("failed to decrypt saved local storage: %v", err)
+		}
+		if err := json.Unmarshal(storageData, &storage); err != nil {
+			r.InvalidateSession(db, site.ID, email)
+			return false, fmt.Errorf("failed to parse saved local storage: %v", err)
+		}
+	}
+
+	// Cookies and storage can only be set on a page of the site
+	if err := r.driver.Get("https://" + site.Name); err != nil {
+		return false, fmt.Errorf("failed to navigate to home page: %v", err)
+	}
+
+	now := uint(time.Now().Unix())
+	for _, cookie := range cookies {
+		if cookie.Expiry > 0 && cookie.Expiry < now {
+			continue
+		}
+		cookie := cookie
+		if err := r.driver.AddCookie(&cookie); err != nil {
+			log.Printf("Warning: Failed to restore cookie %s for %s: %v", cookie.Name, r.browserType, err)
+		}
+	}
+	if len(storage) > 0 {
+		if _, err := r.driver.ExecuteScript(localStorageWriteScript, []interface{}{storage}); err != nil {
+			log.Printf("Warning: Failed to restore local storage for %s: %v", r.browserType, err)
+		}
+	}
+
+	if err := r.driver.Refresh(); err != nil {
+		return false, fmt.Errorf("failed to reload page: %v", err)
+	}
+
+	// The check only decides between the saved session and the login form, so its failure is not
+	// one of the result
+	failures := len(r.assertionFailures)
+	err = r.AssertLoggedIn(site.Name, r.Expect().Within(loginTimeout))
+	r.assertionFailures = r.assertionFailures[:failures]
+	if err == nil {
+		r.collectPageMetrics("Home Page")
+		return true, nil
+	}
+	log.Printf("Saved session of %s not accepted for %s: %v", site.Name, r.browserType, err)
+
+	// The site rejected the saved state, start over from a clean browser
+	r.InvalidateSession(db, site.ID, email)
+	r.clearBrowserState()
+
+	return false, nil
+}
+
+// clearBrowserState removes the cookies and local storage of the current site, logging the browser out
+func (r *BrowserStackRunner) clearBrowserState() {
+	if err := r.driver.DeleteAllCookies(); err != nil {
+		log.Printf("Warning: Failed to clear cookies for %s: %v", r.browserType, err)
+	}
+	if _, err := r.driver.ExecuteScript(`window.localStorage.clear();`, nil); err != nil {
+		log.Printf("Warning: Failed to clear local storage for %s: %v", r.browserType, err)
+	}
+}
+
+// InvalidateSession removes the saved login of the account
+func (r *BrowserStackRunner) InvalidateSession(db *gorm.DB, siteID uint, email string) {
+	if err := db.Where("site_id = ? AND email = ?", siteID, email).Delete(&models.AuthSession{}).Error; err != nil {
+		log.Printf("Warning: Failed to remove saved session for site %d: %v", siteID, err)
+	}
+}
@@ -0,0 +1,22 @@
+package testrunner
+
+import "os"
+
+// siteEnvPrefixes are the prefixes of the per-site settings, as in SENTI_CHAT_REST_ID
+var siteEnvPrefixes = map[string]string{
+	"senti.live":        "SENTI",
+	"shorts.senti.live": "SHORTS_SENTI",
+	"hothinge.com":      "HOTHINGE",
+	"viblys.com":        "VIBLYS",
+}
+
+// siteSetting returns the <SITE>_<name> setting of a site, such as VIBLYS_LOGOUT_SELECTOR,
+// or the fallback when it is not set
+func siteSetting(siteName, name, fallback string) string {
+	if prefix, ok := siteEnvPrefixes[siteName]; ok {
+		if value := os.Getenv(prefix + "_" + name); value != "" {
+			return value
+		}
+	}
+	return fallback
+}
@@ -0,0 +1,82 @@
+package testrunner
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+
+	"gorm.io/gorm"
+	"qa-automation-system/backend/models"
+)
+
+// signatureReplacements strip the parts of an error message that change between runs of the same failure
+var signatureReplacements = []struct {
+	pattern     *regexp.Regexp
+	replacement string
+}{
+	{regexp.MustCompile(`https?://\S+`), "<url>"},
+	{regexp.MustCompile(`\b(chrome|firefox|edge|safari)\b`), "<browser>"},
+	{regexp.MustCompile(`\b[0-9a-f]{16,}\b`), "<id>"},
+	{regexp.MustCompile(`\d+(\.\d+)?`), "<n>"},
+	{regexp.MustCompile(`\s+`), " "},
+}
+
+// FailureSignature identifies failures of a feature on a site with the same cause, from the failure
+// category and the first line of the error log without URLs, browsers, IDs and numbers
+func FailureSignature(siteID, featureID uint, category, errorLog string) string {
+	message := strings.ToLower(strings.TrimSpace(errorLog))
+	if index := strings.Index(message, "\n"); index != -1 {
+		message = message[:index]
+	}
+	for _, r := range signatureReplacements {
+		message = r.pattern.ReplaceAllString(message, r.replacement)
+	}
+
+	sum := sha1.Sum([]byte(fmt.Sprintf("%d|%d|%s|%s", siteID, featureID, category, message)))
+	return hex.EncodeToString(sum[:])
+}
+
+// openTriage signs a failed result and opens its triage, unless it is already triaged. A failure
+// with the signature of a known issue is linked to the bug of that issue instead of waiting for triage.
+func openTriage(db *gorm.DB, result models.Result) {
+	signature := FailureSignature(result.SiteID, result.FeatureID, result.FailureCategory, result.ErrorLog)
+	updates := map[string]interface{}{
+		"failure_signature": signature,
+		"triage_status":     models.TriageNew,
+	}
+
+	var known models.Result
+	err := db.Select("id", "assignee", "bug_url").
+		Where("failure_signature = ? AND triage_status = ? AND id <> ?", signature, models.TriageKnownIssue, result.ID).
+		Order("id DESC").First(&known).Error
+	if err == nil {
+		updates["triage_status"] = models.TriageKnownIssue
+		updates["assignee"] = known.Assignee
+		updates["bug_url"] = known.BugURL
+		log.Printf("Result ID %d matches the known issue of Result ID %d: %s", result.ID, known.ID, known.BugURL)
+	} else if err != gorm.ErrRecordNotFound {
+		log.Printf("Warning: Failed to look up known issues for Result ID %d: %v", result.ID, err)
+	}
+
+	if err := db.Model(&models.Result{}).Where("id = ? AND triage_status IS NULL", result.ID).Updates(updates).Error; err != nil {
+		log.Printf("Warning: Failed to open triage for Result ID %d: %v", result.ID, err)
+	}
+}
+
+// closeRetriedTriage closes the triage that openTriage opened on failed attempts that passed on
+// a later retry, as there is nothing left to triage. Only untouched triage is closed: known issues
+// and attempts someone assigned or linked to a bug meanwhile are kept.
+func closeRetriedTriage(db *gorm.DB, resultIDs []uint) {
+	if len(resultIDs) == 0 {
+		return
+	}
+	if err := db.Model(&models.Result{}).
+		Where("id IN ? AND triage_status = ? AND (assignee IS NULL OR assignee = '') AND (bug_url IS NULL OR bug_url = '')",
+			resultIDs, models.TriageNew).
+		Update("triage_status", nil).Error; err != nil {
+		log.Printf("Warning: Failed to close triage of retried Result IDs %v: %v", resultIDs, err)
+	}
+}
@@ -0,0 +1,41 @@
+package testrunner
+
+import "testing"
+
+func TestFailureSignature(t *testing.T) {
+	base := FailureSignature(1, 2, "navigation", "navigation failed: not on home page, current URL: https://senti.live/?ref=1")
+
+	tests := []struct {
+		name      string
+		siteID    uint
+		featureID uint
+		category  string
+		errorLog  string
+		same      bool
+	}{
+		{"other URL", 1, 2, "navigation", "navigation failed: not on home page, current URL: https://senti.live/login?next=%2Fchat", true},
+		{"case and spacing", 1, 2, "navigation", "  Navigation failed:  not on home page,\tcurrent URL: http://senti.live/", true},
+		{"later lines", 1, 2, "navigation", "navigation failed: not on home page, current URL: https://senti.live/\nPassed on retry, attempt 2", true},
+		{"other message", 1, 2, "navigation", "navigation failed: not on chat page, current URL: https://senti.live/", false},
+		{"other category", 1, 2, "timeout", "navigation failed: not on home page, current URL: https://senti.live/", false},
+		{"other site", 3, 2, "navigation", "navigation failed: not on home page, current URL: https://senti.live/", false},
+		{"other feature", 1, 4, "navigation", "navigation failed: not on home page, current URL: https://senti.live/", false},
+	}
+
+	for _, tt := range tests {
+		got := FailureSignature(tt.siteID, tt.featureID, tt.category, tt.errorLog)
+		if (got == base) != tt.same {
+			t.Errorf("%s: signature equal = %v, want %v", tt.name, got == base, tt.same)
+		}
+	}
+
+	// Browsers, IDs and numbers are stripped too
+	a := FailureSignature(1, 2, "assertion", "assertion failed: expected 3 elements matching .card within 10s on chrome, session 4f2a9c8e1b7d6a5f3e2c")
+	b := FailureSignature(1, 2, "assertion", "assertion failed: expected 5 elements matching .card within 20s on firefox, session 9a8b7c6d5e4f3a2b1c0d")
+	if a != b {
+		t.Errorf("signatures differ by browser, ID and numbers: %s, %s", a, b)
+	}
+	if len(a) != 40 {
+		t.Errorf("signature length = %d, want 40", len(a))
+	}
+}
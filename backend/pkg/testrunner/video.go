@@ -0,0 +1,132 @@
+package testrunner
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// videoStateScript returns the playback state of the <video> element that covers
+// most of the viewport, which is the one the feed is currently showing
+const videoStateScript = `
+	var best = null;
+	var bestArea = 0;
+	var videos = document.querySelectorAll('video');
+	for (var i = 0; i < videos.length; i++) {
+		var rect = videos[i].getBoundingClientRect();
+		var width = Math.max(0, Math.min(rect.right, window.innerWidth) - Math.max(rect.left, 0));
+		var height = Math.max(0, Math.min(rect.bottom, window.innerHeight) - Math.max(rect.top, 0));
+		if (width * height > bestArea) {
+			best = i;
+			bestArea = width * height;
+		}
+	}
+	if (best === null) {
+		return { found: false, count: videos.length };
+	}
+
+	var video = videos[best];
+	return {
+		found: true,
+		count: videos.length,
+		index: best,
+		paused: video.paused,
+		ended: video.ended,
+		current_time: video.currentTime,
+		ready_state: video.readyState,
+		error: video.error ? ('code ' + video.error.code + (video.error.message ? ': ' + video.error.message : '')) : '',
+		src: video.currentSrc || video.src || ''
+	};
+`
+
+// VideoState is the playback state of the visible <video> element
+type VideoState struct {
+	Found       bool    `json:"found"`
+	Count       int     `json:"count"`
+	Index       int     `json:"index"`
+	Paused      bool    `json:"paused"`
+	Ended       bool    `json:"ended"`
+	CurrentTime float64 `json:"current_time"`
+	ReadyState  int     `json:"ready_state"`
+	Error       string  `json:"error"`
+	Src         string  `json:"src"`
+}
+
+// haveFutureData is the HTMLMediaElement readyState from which playback can advance
+const haveFutureData = 3
+
+// VideoState reads the playback state of the visible video
+func (r *BrowserStackRunner) VideoState() (*VideoState, error) {
+	if r.driver == nil {
+		return nil, fmt.Errorf("driver not initialized")
+	}
+
+	response, err := r.driver.ExecuteScriptRaw(videoStateScript, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read video state: %v", err)
+	}
+
+	var reply struct {
+		Value VideoState `json:"value"`
+	}
+	if err := json.Unmarshal(response, &reply); err != nil {
+		return nil, fmt.Errorf("failed to parse video state: %v", err)
+	}
+	if !reply.Value.Found {
+		return nil, fmt.Errorf("no visible <video> element found (%d on page)", reply.Value.Count)
+	}
+	if reply.Value.Error != "" {
+		return nil, fmt.Errorf("video failed to load: %s", reply.Value.Error)
+	}
+
+	return &reply.Value, nil
+}
+
+// WaitForVideoPlaying waits until the visible video is playing and its currentTime advances
+func (r *BrowserStackRunner) WaitForVideoPlaying(timeout time.Duration) (*VideoState, error) {
+	deadline := time.Now().Add(timeout)
+	var last *VideoState
+
+	for {
+		state, err := r.VideoState()
+		if err != nil {
+			return nil, err
+		}
+
+		if !state.Paused && state.ReadyState >= haveFutureData && last != nil &&
+			last.Src == state.Src && state.CurrentTime > last.CurrentTime {
+			return state, nil
+		}
+		last = state
+
+		if time.Now().After(deadline) {
+			return state, fmt.Errorf("video did not start playing within %v (paused: %t, readyState: %d, currentTime: %.2fs)",
+				timeout, state.Paused, state.ReadyState, state.CurrentTime)
+		}
+		time.Sleep(500 * time.Millisecond)
+	}
+}
+
+// WaitForVideoPaused waits until the visible video is paused and its currentTime stops advancing
+func (r *BrowserStackRunner) WaitForVideoPaused(timeout time.Duration) (*VideoState, error) {
+	deadline := time.Now().Add(timeout)
+	var last *VideoState
+
+	for {
+		state, err := r.VideoState()
+		if err != nil {
+			return nil, err
+		}
+
+		if state.Paused && last != nil && last.Src == state.Src && state.CurrentTime == last.CurrentTime {
+			return state, nil
+		}
+		last = state
+
+		if time.Now().After(deadline) {
+			return state, fmt.Errorf("video did not stop playing within %v (paused: %t, currentTime: %.2fs)",
+				timeout, state.Paused, state.CurrentTime)
+		}
+		time.Sleep(500 * time.Millisecond)
+	}
+}
@@ -27,6 +27,9 @@ func SetupRouter(db *gorm.DB) *gin.Engine {
 	deviceController := controllers.NewDeviceController(db)
 	featureController := controllers.NewFeatureController(db)
 	resultController := controllers.NewResultController(db)
+	performanceController := controllers.NewPerformanceController(db)
+	analyticsController := controllers.NewAnalyticsController(db)
+	runController := controllers.NewRunController(db)
 
 	// API routes
 	api := router.Group("/api")
@@ -66,13 +69,50 @@ func SetupRouter(db *gorm.DB) *gin.Engine {
 		{
 			results.GET("", resultController.GetResults)
 			results.GET("/export", resultController.ExportResults)
+			results.GET("/report", resultController.ReportResults)
 			results.GET("/:id", resultController.GetByID)
 			results.POST("", resultController.Create)
+			results.POST("/:id/rerun", resultController.Rerun)
 			results.PUT("/:id", resultController.Update)
 			results.DELETE("/:id", resultController.Delete)
 			results.GET("/:id/details", resultController.GetResultDetails)
+			results.GET("/:id/network", resultController.GetNetworkSummary)
 			results.POST("/:id/details", resultController.CreateResultDetail)
 			results.DELETE("/:id/details/:detail_id", resultController.DeleteResultDetail)
+			results.PUT("/:id/triage", resultController.UpdateTriage)
+			results.GET("/:id/comments", resultController.GetComments)
+			results.POST("/:id/comments", resultController.CreateComment)
+			results.DELETE("/:id/comments/:comment_id", resultController.DeleteComment)
+		}
+
+		// Runs routes
+		runs := api.Group("/runs")
+		{
+			runs.GET("/:id", runController.GetByID)
+			runs.GET("/:id/junit", runController.GetJUnit)
+			runs.GET("/:id/report", runController.GetReport)
+			runs.POST("/:id/rerun", runController.Rerun)
+		}
+
+		// Performance routes
+		performance := api.Group("/performance")
+		{
+			performance.GET("/trends", performanceController.GetTrends)
+			performance.GET("/budgets", performanceController.GetBudgets)
+			performance.POST("/budgets", performanceController.CreateBudget)
+			performance.PUT("/budgets/:id", performanceController.UpdateBudget)
+			performance.DELETE("/budgets/:id", performanceController.DeleteBudget)
+		}
+
+		// Analytics routes
+		analytics := api.Group("/analytics")
+		{
+			analytics.GET("/trends", analyticsController.GetTrends)
+			analytics.GET("/failures", analyticsController.GetFailures)
+			analytics.GET("/flaky", analyticsController.GetFlaky)
+			analytics.GET("/quarantines", analyticsController.GetQuarantines)
+			analytics.POST("/quarantines", analyticsController.CreateQuarantine)
+			analytics.DELETE("/quarantines/:id", analyticsController.DeleteQuarantine)
 		}
 	}
 